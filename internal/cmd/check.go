@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+// runCheck reports all the problems of the task definitions (including the shell syntax errors)
+// with their location, and fails if there is any.
+func runCheck(filename string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		tasks, problems, err := task.Check(filename)
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) == 0 && len(problems) == 0 {
+			return fmt.Errorf("%w in %s", errNoTasks, filename)
+		}
+
+		for _, task := range tasks {
+			for _, step := range task.Steps {
+				problems = append(problems, shell.Check(step)...)
+			}
+		}
+
+		task.SortProblems(problems)
+
+		for _, problem := range problems {
+			fmt.Fprintln(cmd.OutOrStdout(), problem)
+		}
+
+		if len(problems) != 0 {
+			return fmt.Errorf("%d %w", len(problems), errProblems)
+		}
+
+		return nil
+	}
+}
+
+var errProblems = errors.New("problem(s) found")
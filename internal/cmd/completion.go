@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/szkiba/cdo/internal/environ"
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
+func runCompletion(cmd *cobra.Command, _ []string) error {
+	shellname, err := cmd.Flags().GetString("completion")
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+
+	switch shellname {
+	case shellBash:
+		return cmd.Root().GenBashCompletionV2(out, true)
+	case shellZsh:
+		return cmd.Root().GenZshCompletion(out)
+	case shellFish:
+		return cmd.Root().GenFishCompletion(out, true)
+	case shellPowerShell:
+		return cmd.Root().GenPowerShellCompletionWithDesc(out)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownShell, shellname)
+	}
+}
+
+// isCompletion reports whether the arguments are a dynamic completion request from the completion script.
+func isCompletion(args []string) bool {
+	return len(args) != 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd)
+}
+
+func registerCompletions(root *cobra.Command, dir *string) {
+	root.ValidArgsFunction = func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeFileToken(toComplete)
+	}
+
+	_ = root.RegisterFlagCompletionFunc("file", func(
+		_ *cobra.Command, _ []string, _ string,
+	) ([]string, cobra.ShellCompDirective) {
+		return []string{"md"}, cobra.ShellCompDirectiveFilterFileExt
+	})
+
+	_ = root.RegisterFlagCompletionFunc("env", func(
+		_ *cobra.Command, _ []string, toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		dotenv := environ.New(nil)
+		if err := dotenv.Load(*dir); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		return completeAssignments(toComplete, keys(dotenv)), cobra.ShellCompDirectiveNoSpace
+	})
+
+	fixed := func(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return values, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+
+	_ = root.RegisterFlagCompletionFunc("completion", fixed(shellBash, shellZsh, shellFish, shellPowerShell))
+	_ = root.RegisterFlagCompletionFunc("format", fixed(formatText, formatJSON))
+}
+
+func registerTaskCompletions(sub *cobra.Command, task *task.Task) {
+	sub.ValidArgsFunction = func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if strings.HasPrefix(toComplete, "@") {
+			return completeFileToken(toComplete)
+		}
+
+		if name, value, found := strings.Cut(toComplete, "="); found {
+			for _, param := range task.Params {
+				if param.Var() == name {
+					return completeValues(name+"=", value, param.Enum), cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return completeAssignments(toComplete, shell.Vars(task.Steps)), cobra.ShellCompDirectiveNoSpace
+	}
+
+	for _, param := range task.Params {
+		if len(param.Enum) == 0 {
+			continue
+		}
+
+		_ = sub.RegisterFlagCompletionFunc(param.Name,
+			func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				return completeValues("", toComplete, param.Enum), cobra.ShellCompDirectiveNoFileComp
+			},
+		)
+	}
+}
+
+// completeFileToken completes the @file shortcut of the --file flag.
+func completeFileToken(toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !strings.HasPrefix(toComplete, "@") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	matches, err := filepath.Glob(toComplete[1:] + "*")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+
+		if info.IsDir() {
+			completions = append(completions, "@"+match+string(filepath.Separator))
+		} else if strings.EqualFold(filepath.Ext(match), ".md") {
+			completions = append(completions, "@"+match)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeAssignments(toComplete string, names []string) []string {
+	var completions []string
+
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"=")
+		}
+	}
+
+	return completions
+}
+
+func completeValues(prefix string, toComplete string, values []string) []string {
+	var completions []string
+
+	for _, value := range values {
+		if strings.HasPrefix(value, toComplete) {
+			completions = append(completions, prefix+value)
+		}
+	}
+
+	return completions
+}
+
+func keys(env environ.Environ) []string {
+	all := make([]string, 0, len(env))
+
+	for key := range env {
+		all = append(all, key)
+	}
+
+	sort.Strings(all)
+
+	return all
+}
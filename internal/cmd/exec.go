@@ -0,0 +1,432 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/szkiba/cdo/internal/environ"
+	"github.com/szkiba/cdo/internal/fingerprint"
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+type executor struct {
+	tasks      map[string]*task.Task
+	dir        string
+	env        environ.Environ
+	jobs       int
+	dryRun     bool
+	grace      time.Duration
+	timeout    time.Duration
+	merge      bool
+	keepGoing  bool
+	force      bool
+	watching   bool
+	watchGlobs []string
+	utils      []string
+	busybox    string
+	once       *once
+	then       [][]string
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+func (e *executor) run(ctx context.Context, targets [][]string) error {
+	// the run-once bookkeeping is shared by the cdo commands of the scripts
+	if e.once == nil {
+		top := *e
+		top.once = newOnce()
+
+		return top.run(ctx, targets)
+	}
+
+	nodes, err := task.ResolveAll(e.tasks, targets)
+	if err != nil {
+		return err
+	}
+
+	invocations, err := e.prepare(nodes)
+	if err != nil {
+		return err
+	}
+
+	if e.dryRun {
+		return e.plan(nodes, invocations)
+	}
+
+	return e.schedule(ctx, nodes, invocations)
+}
+
+// start runs the tasks, in watch mode until interrupted.
+func (e *executor) start(ctx context.Context, targets [][]string) error {
+	names := make([]string, 0, len(targets))
+
+	for _, target := range targets {
+		names = append(names, target[0])
+	}
+
+	ctx, cancel := e.deadline(ctx, names)
+	defer cancel()
+
+	if e.watching && !e.dryRun {
+		return e.watch(ctx, targets)
+	}
+
+	return e.run(ctx, targets)
+}
+
+// deadline applies the timeout of the whole invocation (if any) to the context.
+func (e *executor) deadline(ctx context.Context, names []string) (context.Context, context.CancelFunc) {
+	if e.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeoutCause(ctx, e.timeout,
+		fmt.Errorf("%w: %s (%s)", errTimeout, strings.Join(names, " + "), e.timeout))
+}
+
+// plan prints the execution plan: the tasks in execution order with the expanded scripts.
+func (e *executor) plan(nodes []*task.Node, invocations map[*task.Node]*invocation) error {
+	for idx, node := range nodes {
+		inv := invocations[node]
+
+		words := make([]string, 0, len(node.Args)+1)
+
+		for _, word := range append([]string{node.Task.Name}, node.Args...) {
+			if quoted, err := syntax.Quote(word, syntax.LangBash); err == nil {
+				word = quoted
+			}
+
+			words = append(words, word)
+		}
+
+		fmt.Fprintf(e.stdout, "# %d. %s\n", idx+1, strings.Join(words, " "))
+
+		if node.Task.Timeout > 0 {
+			fmt.Fprintf(e.stdout, "# timeout: %s\n", node.Task.Timeout)
+		}
+
+		_, upToDate, err := e.fingerprint(node, inv)
+		if err != nil {
+			return err
+		}
+
+		if upToDate {
+			fmt.Fprint(e.stdout, "# up to date\n\n")
+
+			continue
+		}
+
+		for idx, step := range node.Task.Steps {
+			if err := e.planStep(inv, step, node.Task.Steps[:idx]); err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintln(e.stdout)
+	}
+
+	return nil
+}
+
+// planStep prints the step with the known variables expanded, before are the earlier steps of the task.
+func (e *executor) planStep(inv *invocation, step *task.Step, before []*task.Step) error {
+	if !step.Supports(runtime.GOOS) {
+		fmt.Fprintf(e.stdout, "# skipped on %s (os=%s)\n", runtime.GOOS, strings.Join(step.OS, ","))
+
+		return nil
+	}
+
+	if len(step.If) != 0 {
+		fmt.Fprintf(e.stdout, "# if: %s\n", step.If)
+	}
+
+	if len(step.Dir) != 0 {
+		fmt.Fprintf(e.stdout, "# dir: %s\n", step.Dir)
+	}
+
+	if !step.Embedded() {
+		fmt.Fprintf(e.stdout, "# %s\n%s", strings.Join(step.Interpreter, " "), step.Script)
+
+		return nil
+	}
+
+	script, err := shell.Expand(step, before, inv.args, inv.env)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.stdout.Write(script)
+
+	return err
+}
+
+// invocation contains the positional arguments and the variables of a node.
+type invocation struct {
+	args []string
+	env  environ.Environ
+}
+
+// inputs returns the positional arguments and the parameter values, which are part of the fingerprint.
+func (inv *invocation) inputs(t *task.Task) []string {
+	inputs := append([]string{}, inv.args...)
+
+	for _, param := range t.Params {
+		inputs = append(inputs, param.Name+"="+inv.env[param.Var()])
+	}
+
+	return inputs
+}
+
+// prepare binds the parameters of all nodes, so invalid parameter values are reported before running anything.
+func (e *executor) prepare(nodes []*task.Node) (map[*task.Node]*invocation, error) {
+	invocations := make(map[*task.Node]*invocation, len(nodes))
+
+	for _, node := range nodes {
+		vars, args, err := node.Task.Bind(node.Args, e.env)
+		if err != nil {
+			return nil, err
+		}
+
+		env := e.env
+		if len(vars) != 0 {
+			env = maps.Clone(e.env)
+			env.Override(vars)
+		}
+
+		invocations[node] = &invocation{args: args, env: env}
+	}
+
+	return invocations, nil
+}
+
+type result struct {
+	node *task.Node
+	err  error
+}
+
+// schedule executes the nodes (in topological order) using at most e.jobs
+// concurrent workers. A node is started when all of its dependencies are done.
+// After the first failure no new node is started and the running ones are canceled,
+// unless e.keepGoing is set: then only the nodes depending on the failed one are skipped.
+//
+//nolint:funlen
+func (e *executor) schedule(parent context.Context, nodes []*task.Node, invocations map[*task.Node]*invocation) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	pending := make(map[*task.Node]int, len(nodes))
+	dependents := make(map[*task.Node][]*task.Node, len(nodes))
+	followers := make(map[*task.Node][]*task.Node, len(nodes))
+	skipped := make(map[*task.Node]struct{})
+	ready := make([]*task.Node, 0, len(nodes))
+
+	for _, node := range nodes {
+		pending[node] = len(node.Deps)
+
+		for _, dep := range node.Deps {
+			dependents[dep] = append(dependents[dep], node)
+		}
+
+		if node.After != nil {
+			pending[node]++
+			followers[node.After] = append(followers[node.After], node)
+		}
+
+		if pending[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	release := func(node *task.Node) {
+		pending[node]--
+
+		if _, skip := skipped[node]; !skip && pending[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	// skip marks the dependents of the failed node as skipped, the followers can still be started
+	var skip func(node *task.Node)
+
+	skip = func(node *task.Node) {
+		for _, dep := range dependents[node] {
+			if _, done := skipped[dep]; !done {
+				skipped[dep] = struct{}{}
+
+				skip(dep)
+			}
+		}
+
+		for _, follower := range followers[node] {
+			release(follower)
+		}
+	}
+
+	results := make(chan result)
+	running := 0
+
+	var errs []error
+
+	for len(ready) != 0 || running != 0 {
+		for ctx.Err() == nil && running < max(e.jobs, 1) && len(ready) != 0 {
+			node := ready[0]
+			ready = ready[1:]
+			running++
+
+			go func() {
+				results <- result{node: node, err: e.exec(ctx, node, invocations[node])}
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+
+		if res.err != nil {
+			if !errors.Is(res.err, context.Canceled) && parent.Err() == nil {
+				errs = append(errs, res.err)
+			}
+
+			if e.keepGoing && parent.Err() == nil {
+				skip(res.node)
+			} else {
+				cancel()
+			}
+
+			continue
+		}
+
+		for _, node := range dependents[res.node] {
+			release(node)
+		}
+
+		for _, node := range followers[res.node] {
+			release(node)
+		}
+	}
+
+	if len(errs) == 0 {
+		return context.Cause(parent)
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return errors.Join(errs...)
+}
+
+// exec runs the steps of the node, unless the same task invocation has already been executed
+// (by a cdo command of a script) during the current invocation.
+func (e *executor) exec(ctx context.Context, node *task.Node, inv *invocation) error {
+	if len(node.Task.Steps) == 0 {
+		return nil
+	}
+
+	done, err := e.once.begin(ctx, node)
+	if done || err != nil {
+		return err
+	}
+
+	err = e.execSteps(withCaller(ctx, node.Key()), node, inv)
+
+	e.once.end(node, err == nil)
+
+	return err
+}
+
+// execSteps runs the steps of the node. When the context is canceled (for example because of a timeout),
+// the cause of the cancellation is returned instead of the error of the interrupted script.
+func (e *executor) execSteps(ctx context.Context, node *task.Node, inv *invocation) error {
+	sum, upToDate, err := e.fingerprint(node, inv)
+	if err != nil {
+		return err
+	}
+
+	if upToDate {
+		fmt.Fprintf(e.stderr, "%s is up to date\n", node.Task.Name)
+
+		return nil
+	}
+
+	if timeout := node.Task.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w: %s (%s)", errTimeout, node.Task.Name, timeout))
+		defer cancel()
+	}
+
+	stderr := e.stderr
+	if e.merge {
+		stderr = e.stdout
+	}
+
+	err = shell.Run(ctx, node.Task.Name, inv.args, node.Task.Steps, &shell.Options{
+		Dir:         e.dir,
+		Env:         inv.env,
+		GracePeriod: e.grace,
+		Stdin:       e.stdin,
+		Stdout:      e.stdout,
+		Stderr:      stderr,
+		Utils:       e.utils,
+		Busybox:     e.busybox,
+		Command:     e.command,
+	})
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+
+	if err == nil && len(sum) != 0 {
+		err = fingerprint.Save(node.Task, e.stateDir(), inv.inputs(node.Task), sum)
+	}
+
+	return err
+}
+
+// fingerprint returns the fingerprint of the node (empty if it has no sources and generated files)
+// and reports whether the node can be skipped, because its generated files exist
+// and its fingerprint is unchanged since the last successful execution.
+func (e *executor) fingerprint(node *task.Node, inv *invocation) (string, bool, error) {
+	if !fingerprint.Incremental(node.Task) {
+		return "", false, nil
+	}
+
+	dir := e.taskDir(node.Task)
+
+	inputs := inv.inputs(node.Task)
+
+	sum, err := fingerprint.Sum(node.Task, dir, inputs)
+	if err != nil || e.force {
+		return sum, false, err
+	}
+
+	upToDate, err := fingerprint.UpToDate(node.Task, dir, e.stateDir(), inputs, sum)
+
+	return sum, upToDate, err
+}
+
+// taskDir returns the directory of the task definitions (the patterns are relative to it).
+func (e *executor) taskDir(t *task.Task) string {
+	if len(t.Dir) != 0 {
+		return t.Dir
+	}
+
+	return e.dir
+}
+
+func (e *executor) stateDir() string {
+	return filepath.Join(e.dir, fingerprint.StateDir)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/environ"
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/interp"
+)
+
+func taskdefs(sections ...string) []byte {
+	var buff strings.Builder
+
+	buff.WriteString("# Tasks\n")
+
+	for _, section := range sections {
+		name, rest, _ := strings.Cut(section, "\n")
+		buff.WriteString("\n## " + name + " - Task " + name + "\n\n" + rest + "\n")
+	}
+
+	return []byte(buff.String())
+}
+
+func script(lines string) string {
+	return "```bash\n" + lines + "\n```\n"
+}
+
+func TestExecutorSchedule(t *testing.T) {
+	t.Parallel()
+
+	tasks, err := task.Load(taskdefs(
+		"a\n"+script("echo a"),
+		"b\n"+script("echo b"),
+		"all\nRequires\n: a, b\n\n"+script("echo all"),
+		"fail\n"+script("echo fail; exit 3"),
+		"broken\nRequires\n: fail, a\n\n"+script("echo broken"),
+		"hang\nTimeout\n: 50ms\n\n"+script("echo hang; while :; do :; done"),
+		"hung\nRequires\n: hang\n\n"+script("echo hung"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		targets   [][]string
+		keepGoing bool
+		canceled  bool
+		output    string
+		err       error
+		status    uint8
+	}{
+		{name: "dependencies first", targets: [][]string{{"all"}}, output: "a b all"},
+		{name: "sequence", targets: [][]string{{"b"}, {"all"}}, output: "b a all"},
+		{name: "failure stops", targets: [][]string{{"broken"}}, output: "fail", status: 3},
+		{name: "failure stops sequence", targets: [][]string{{"fail"}, {"b"}}, output: "fail", status: 3},
+		{name: "keep going", targets: [][]string{{"broken"}}, keepGoing: true, output: "fail a", status: 3},
+		{name: "keep going sequence", targets: [][]string{{"fail"}, {"b"}}, keepGoing: true, output: "fail b", status: 3},
+		{name: "timeout", targets: [][]string{{"hung"}, {"a"}}, output: "hang", err: errTimeout},
+		{
+			name: "timeout keep going", targets: [][]string{{"hung"}, {"a"}}, keepGoing: true,
+			output: "hang a", err: errTimeout,
+		},
+		{name: "canceled", targets: [][]string{{"all"}}, canceled: true, err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+
+			exec := &executor{
+				tasks:     tasks,
+				dir:       t.TempDir(),
+				env:       environ.New(nil),
+				jobs:      1,
+				keepGoing: tt.keepGoing,
+				utils:     []string{shell.UtilsBuiltin},
+				busybox:   shell.BusyboxNever,
+				stdout:    &stdout,
+				stderr:    &stderr,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if tt.canceled {
+				cancel()
+			}
+
+			err := exec.run(ctx, tt.targets)
+
+			if got := strings.Join(strings.Fields(stdout.String()), " "); got != tt.output {
+				t.Errorf("output = %q, want %q", got, tt.output)
+			}
+
+			status, isStatus := interp.IsExitStatus(err)
+
+			switch {
+			case tt.status != 0 && (!isStatus || status != tt.status):
+				t.Errorf("run() error = %v, want exit status %d", err, tt.status)
+			case tt.status == 0 && !errors.Is(err, tt.err):
+				t.Errorf("run() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExecutorScheduleJobs(t *testing.T) {
+	t.Parallel()
+
+	// first waits for second, so they finish only if they run in parallel
+	tasks, err := task.Load(taskdefs(
+		"first\nTimeout\n: 10s\n\n"+script("while [ ! -e second.done ]; do :; done; echo first"),
+		"second\n"+script(": > second.done; echo second"),
+		"both\nRequires\n: first, second\n\n"+script("echo both"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+
+	exec := &executor{
+		tasks:   tasks,
+		dir:     t.TempDir(),
+		env:     environ.New(nil),
+		jobs:    2,
+		utils:   []string{shell.UtilsBuiltin},
+		busybox: shell.BusyboxNever,
+		stdout:  &stdout,
+		stderr:  &stdout,
+	}
+
+	if err := exec.run(context.Background(), [][]string{{"both"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(strings.Fields(stdout.String()), " "); got != "second first both" {
+		t.Errorf("output = %q, want %q", got, "second first both")
+	}
+}
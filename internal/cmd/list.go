@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// listing is the machine-readable task listing, its format is part of the stable interface.
+type listing struct {
+	File  string         `json:"file"`
+	Dir   string         `json:"dir"`
+	Tasks []*listingTask `json:"tasks"`
+}
+
+type listingTask struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Short     string            `json:"short"`
+	Long      string            `json:"long"`
+	File      string            `json:"file"`
+	Line      int               `json:"line"`
+	Dir       string            `json:"dir"`
+	Requires  []*listingRequire `json:"requires"`
+	Params    []*listingParam   `json:"params"`
+	Timeout   string            `json:"timeout,omitempty"`
+	Sources   []string          `json:"sources,omitempty"`
+	Generates []string          `json:"generates,omitempty"`
+	Script    string            `json:"script"`
+	Steps     []*listingStep    `json:"steps"`
+}
+
+type listingRequire struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+type listingParam struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description"`
+}
+
+type listingStep struct {
+	Lang        string            `json:"lang"`
+	Line        int               `json:"line"`
+	Script      string            `json:"script"`
+	Interpreter []string          `json:"interpreter,omitempty"`
+	Attrs       map[string]string `json:"attrs"`
+}
+
+func runList(filename string, dir string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		tasks, err := loadTasks(filename)
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case formatText:
+			for _, task := range commandTasks(tasks) {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", task.Name, task.Short)
+			}
+
+			return nil
+		case formatJSON:
+			list, err := newListing(filename, dir, commandTasks(tasks))
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(list)
+		default:
+			return fmt.Errorf("%w: %s", errUnknownFormat, format)
+		}
+	}
+}
+
+func newListing(filename string, dir string, tasks []*task.Task) (*listing, error) {
+	absname, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	absdir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &listing{File: absname, Dir: absdir, Tasks: make([]*listingTask, 0, len(tasks))}
+
+	for _, task := range tasks {
+		list.Tasks = append(list.Tasks, newListingTask(task, absdir))
+	}
+
+	return list, nil
+}
+
+func newListingTask(source *task.Task, dir string) *listingTask {
+	filename, err := filepath.Abs(source.File)
+	if err != nil {
+		filename = source.File
+	}
+
+	if len(source.Dir) != 0 {
+		if dir, err = filepath.Abs(source.Dir); err != nil {
+			dir = source.Dir
+		}
+	}
+
+	item := &listingTask{
+		Name:      source.Name,
+		Namespace: source.Namespace,
+		Short:     source.Short,
+		Long:      source.Long,
+		File:      filename,
+		Line:      source.Line,
+		Dir:       dir,
+		Requires:  make([]*listingRequire, 0, len(source.Requires)),
+		Params:    make([]*listingParam, 0, len(source.Params)),
+		Sources:   source.Sources,
+		Generates: source.Generates,
+		Script:    string(source.Script()),
+		Steps:     make([]*listingStep, 0, len(source.Steps)),
+	}
+
+	if source.Timeout > 0 {
+		item.Timeout = source.Timeout.String()
+	}
+
+	for _, req := range source.Requires {
+		item.Requires = append(item.Requires, &listingRequire{Name: req[0], Args: append([]string{}, req[1:]...)})
+	}
+
+	for _, param := range source.Params {
+		item.Params = append(item.Params, &listingParam{
+			Name:        param.Name,
+			Type:        param.Type,
+			Default:     param.Default,
+			Enum:        param.Enum,
+			Description: param.Description,
+		})
+	}
+
+	for _, step := range source.Steps {
+		item.Steps = append(item.Steps, &listingStep{
+			Lang:        step.Lang,
+			Line:        step.Line,
+			Script:      string(step.Script),
+			Interpreter: step.Interpreter,
+			Attrs:       step.Attrs,
+		})
+	}
+
+	return item
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+// command runs the cdo command of a task script in-process, using the loaded tasks and the variables of the script.
+// Only task invocations are handled, other commands (for example cdo --list) are executed by the cdo executable.
+func (e *executor) command(ctx context.Context, args []string, opts *shell.Options) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	if _, found := e.tasks[args[0]]; !found {
+		return false, nil
+	}
+
+	nested := *e
+	nested.env = opts.Env
+	nested.stdin = opts.Stdin
+	nested.stdout = opts.Stdout
+	nested.stderr = opts.Stderr
+
+	var first []string
+
+	first, nested.then = splitSequence(args)
+
+	targets, err := nested.sequence(pflag.NewFlagSet(appname, pflag.ContinueOnError), first)
+	if err != nil {
+		return true, err
+	}
+
+	return true, nested.run(ctx, targets)
+}
+
+// once is the run-once bookkeeping of an invocation. The task invocations are executed only once,
+// even if they are requested again by the cdo commands of the scripts.
+// A request for a running task invocation waits until it finishes.
+type once struct {
+	mu   sync.Mutex
+	runs map[string]*onceRun
+	// waits contains the task invocation waited for by the script of a running task invocation
+	waits map[string]string
+}
+
+type onceRun struct {
+	done chan struct{}
+	ok   bool
+}
+
+func newOnce() *once {
+	return &once{runs: make(map[string]*onceRun), waits: make(map[string]string)}
+}
+
+// callersKey is the context key of the task invocations in the call chain (the last one is the innermost).
+type callersKey struct{}
+
+func callers(ctx context.Context) []string {
+	chain, _ := ctx.Value(callersKey{}).([]string)
+
+	return chain
+}
+
+func withCaller(ctx context.Context, key string) context.Context {
+	chain := callers(ctx)
+
+	return context.WithValue(ctx, callersKey{}, append(chain[:len(chain):len(chain)], key))
+}
+
+// begin reports whether the task invocation has already been executed successfully.
+// Requesting a task invocation in its own call chain (or waiting for each other) would never end, it is an error.
+func (o *once) begin(ctx context.Context, node *task.Node) (bool, error) {
+	key := node.Key()
+	chain := callers(ctx)
+
+	if slices.Contains(chain, key) {
+		return false, fmt.Errorf("%w: %s", errRecursiveTask, node.Task.Name)
+	}
+
+	for {
+		o.mu.Lock()
+
+		run, seen := o.runs[key]
+		if !seen {
+			o.runs[key] = &onceRun{done: make(chan struct{})}
+			o.mu.Unlock()
+
+			return false, nil
+		}
+
+		if len(chain) != 0 {
+			caller := chain[len(chain)-1]
+
+			if o.cycle(key, caller) {
+				o.mu.Unlock()
+
+				return false, fmt.Errorf("%w: %s", errRecursiveTask, node.Task.Name)
+			}
+
+			o.waits[caller] = key
+		}
+
+		o.mu.Unlock()
+
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+		}
+
+		o.mu.Lock()
+		if len(chain) != 0 {
+			delete(o.waits, chain[len(chain)-1])
+		}
+		o.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return false, context.Cause(ctx)
+		}
+
+		// a failed task invocation can be executed again
+		if run.ok {
+			return true, nil
+		}
+	}
+}
+
+// cycle reports whether the running task invocation waits (directly or indirectly) for the caller.
+func (o *once) cycle(key string, caller string) bool {
+	for next, found := key, true; found; next, found = o.waits[next] {
+		if next == caller {
+			return true
+		}
+	}
+
+	return false
+}
+
+// end records the result of the task invocation and releases the waiting requests.
+func (o *once) end(node *task.Node, ok bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	run := o.runs[node.Key()]
+	run.ok = ok
+
+	if !ok {
+		delete(o.runs, node.Key())
+	}
+
+	close(run.done)
+}
+
+var errRecursiveTask = errors.New("recursive task invocation")
@@ -7,15 +7,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/szkiba/cdo/internal/environ"
+	"github.com/szkiba/cdo/internal/graph"
 	"github.com/szkiba/cdo/internal/makefile"
 	"github.com/szkiba/cdo/internal/shell"
 	"github.com/szkiba/cdo/internal/task"
+	"github.com/szkiba/cdo/internal/workspace"
 )
 
 //nolint:gochecknoglobals
@@ -47,21 +51,13 @@ func findReadme() (string, string, error) {
 }
 
 func findContributing() (string, string, error) {
-	const base = "CONTRIBUTING.md"
-
-	abs, err := filepath.Abs(base)
+	abs, err := filepath.Abs(".")
 	if err != nil {
 		return "", "", err
 	}
 
-	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
-		files := []string{
-			filepath.Clean(filepath.Join(dir, base)),
-			filepath.Clean(filepath.Join(dir, "docs", base)),
-			filepath.Clean(filepath.Join(dir, ".github", base)),
-		}
-
-		for _, filename := range files {
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		for _, filename := range workspace.Candidates(dir) {
 			if _, err := os.Stat(filename); err == nil {
 				return filename, dir, nil
 			}
@@ -84,6 +80,8 @@ func findDefinitions() (string, string, error) {
 	return filename, dir, err
 }
 
+const defaultGracePeriod = 10 * time.Second
+
 //go:embed help.txt
 var help string
 
@@ -104,7 +102,7 @@ func newCommand() *cobra.Command {
 	return root
 }
 
-func preParsePersistentFlags(cmd *cobra.Command, args []string) (bool, *cobra.Command, error) {
+func preParsePersistentFlags(cmd *cobra.Command, args []string, dir string) (bool, *cobra.Command, error) {
 	flags := cmd.PersistentFlags()
 
 	err := flags.Parse(args)
@@ -116,6 +114,12 @@ func preParsePersistentFlags(cmd *cobra.Command, args []string) (bool, *cobra.Co
 		return true, cmd, nil
 	}
 
+	if cflag := flags.Lookup("completion"); cflag.Changed {
+		cmd.RunE = runCompletion
+
+		return true, cmd, nil
+	}
+
 	filename, err := flags.GetString("file")
 	if err != nil {
 		return true, nil, err
@@ -127,8 +131,30 @@ func preParsePersistentFlags(cmd *cobra.Command, args []string) (bool, *cobra.Co
 		return true, cmd, nil
 	}
 
+	if fflag := flags.Lookup("file"); fflag.Changed {
+		dir = filepath.Dir(filename)
+	}
+
+	if cflag := flags.Lookup("check"); cflag.Changed {
+		cmd.RunE = runCheck(filename)
+
+		return true, cmd, nil
+	}
+
 	if mflag := flags.Lookup("makefile"); mflag.Changed {
-		cmd.RunE = runMake(filename)
+		cmd.RunE = runMake(filename, dir)
+
+		return true, cmd, nil
+	}
+
+	if gflag := flags.Lookup("graph"); gflag.Changed {
+		cmd.RunE = runGraph(filename)
+
+		return true, cmd, nil
+	}
+
+	if lflag := flags.Lookup("list"); lflag.Changed {
+		cmd.RunE = runList(filename, dir)
 
 		return true, cmd, nil
 	}
@@ -145,8 +171,15 @@ func New(args []string) (*cobra.Command, error) {
 		return nil, err
 	}
 
+	exec := &executor{env: env}
+	recursive := false
+
 	root := newCommand()
 	root.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
+		if err := checkUtils(exec.utils); err != nil {
+			return err
+		}
+
 		if err := env.Load(dir); err != nil {
 			return err
 		}
@@ -161,17 +194,53 @@ func New(args []string) (*cobra.Command, error) {
 	flags.VarP(&flagenv, "env", "e", "Set environment variable(s)")
 	flags.StringVarP(&filename, "file", "f", filename, "Task definitions file")
 	flags.StringP("makefile", "m", "", "Makefile file")
+	flags.IntVarP(&exec.jobs, "jobs", "j", 1, "Number of tasks to run in parallel")
+	flags.BoolVarP(&exec.dryRun, "dry-run", "n", false, "Print the execution plan without executing anything")
+	flags.DurationVar(&exec.grace, "grace-period", defaultGracePeriod,
+		"Time to wait after interrupting the commands before killing them")
+	flags.DurationVar(&exec.timeout, "timeout", 0, "Maximum duration of the whole invocation (0 means no limit)")
+	flags.BoolVar(&exec.merge, "merge-output", false, "Redirect the standard error of the tasks to the standard output")
+	flags.BoolVar(&exec.watching, "watch", false, "Rerun the task when its source files change")
+	flags.StringSliceVar(&exec.watchGlobs, "watch-glob", nil,
+		"Glob pattern of the files to watch instead of the sources of the task")
+	flags.BoolVar(&exec.force, "force", false, "Execute the tasks even if they are up to date")
+	flags.StringSliceVar(&exec.utils, "utils", shell.DefaultUtils(),
+		"Precedence of the command sources (busybox, native, builtin)")
+	flags.StringVar(&exec.busybox, "busybox", shell.BusyboxAuto,
+		"Use busybox (auto, always, never or path of the busybox executable)")
+	flags.BoolVarP(&exec.keepGoing, "keep-going", "k", false,
+		"Continue after a failure with the tasks not depending on the failed one")
+	flags.StringP("graph", "g", "", "Print the dependency graph (mermaid or dot) of all tasks or the given task")
+	flags.Lookup("graph").NoOptDefVal = graph.FormatMermaid
+	flags.BoolP("list", "l", false, "List the tasks")
+	flags.String("format", formatText, "Format of the task list (text or json)")
+	flags.BoolVarP(&recursive, "recursive", "r", false, "Run the task in every project of the workspace that defines it")
+	flags.Bool("check", false, "Check the task definitions and report all problems")
+	flags.String("completion", "", "Print the completion script for the shell (bash, zsh, fish or powershell)")
 	flags.BoolP("version", "V", false, "Print version")
 	flags.BoolP("help", "h", false, "Print usage")
 
-	args = token2flag(args, flags.Lookup("env"), flags.Lookup("file"))
+	if isCompletion(args) {
+		// the last argument is the one to be completed, it is kept as is
+		last := len(args) - 1
+		args = append(token2flag(args[:last], flags.Lookup("env"), flags.Lookup("file")), args[last])
+	} else {
+		args = token2flag(args, flags.Lookup("env"), flags.Lookup("file"))
+	}
+
+	registerCompletions(root, &dir)
+
+	head := args
+	if !isCompletion(args) {
+		head, exec.then = splitSequence(args)
+	}
 
-	root.SetArgs(args)
+	root.SetArgs(head)
 
 	flags.ParseErrorsWhitelist = pflag.ParseErrorsWhitelist{UnknownFlags: true}
 	flags.SetOutput(io.Discard)
 
-	done, cmd, err := preParsePersistentFlags(root, args)
+	done, cmd, err := preParsePersistentFlags(root, args, dir)
 	if done {
 		return cmd, err
 	}
@@ -181,7 +250,21 @@ func New(args []string) (*cobra.Command, error) {
 		dir = filepath.Dir(filename)
 	}
 
-	if err := addCommands(root, env, filename, dir); err != nil {
+	if recursive {
+		exec.dir = dir
+		root.RunE = runRecursive(filename, dir, exec, flagenv)
+
+		return root, nil
+	}
+
+	if project, name, found := lookupProject(filename, dir, flags.Arg(0)); found {
+		filename, dir = project.File, project.Dir
+		root.SetArgs(replaceArg(head, flags.Arg(0), name))
+	}
+
+	exec.dir = dir
+
+	if err := addCommands(root, exec, filename); err != nil {
 		if errors.Is(err, errNoTasks) {
 			root.RunE = runNoTasks
 		} else {
@@ -212,36 +295,78 @@ func runNoTasks(cmd *cobra.Command, _ []string) error {
 	return errNoTasks
 }
 
-func runMake(filename string) func(*cobra.Command, []string) error {
-	return func(cmd *cobra.Command, _ []string) error {
-		taskdefs, err := os.ReadFile(filepath.Clean(filename))
+func loadTasks(filename string) (map[string]*task.Task, error) {
+	tasks, err := task.LoadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoTasks, filename)
+	}
+
+	return tasks, nil
+}
+
+func sortedTasks(tasks map[string]*task.Task) []*task.Task {
+	all := make([]*task.Task, 0, len(tasks))
+
+	for _, task := range tasks {
+		all = append(all, task)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	return all
+}
+
+func runGraph(filename string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		tasks, err := loadTasks(filename)
 		if err != nil {
 			return err
 		}
 
-		tasks, err := task.Load(taskdefs)
+		format, err := cmd.Flags().GetString("graph")
 		if err != nil {
 			return err
 		}
 
-		if len(tasks) == 0 {
-			return fmt.Errorf("%w in %s", errNoTasks, filename)
+		all := commandTasks(tasks)
+
+		if len(args) != 0 {
+			if all, err = graph.Closure(tasks, args[0]); err != nil {
+				return err
+			}
 		}
 
-		outname, err := cmd.Flags().GetString("makefile")
+		contents, err := graph.Generate(format, all)
 		if err != nil {
 			return err
 		}
 
-		all := make([]*task.Task, 0, len(tasks))
+		_, err = cmd.OutOrStdout().Write(contents)
+
+		return err
+	}
+}
 
-		for _, task := range tasks {
-			all = append(all, task)
+func runMake(filename string, dir string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		tasks, err := loadTasks(filename)
+		if err != nil {
+			return err
 		}
 
-		sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+		outname, err := cmd.Flags().GetString("makefile")
+		if err != nil {
+			return err
+		}
 
-		contents := makefile.Generate(appname, relative(filename, outname), all)
+		contents, err := makefile.Generate(appname, relative(filename, outname), dir, sortedTasks(tasks))
+		if err != nil {
+			return err
+		}
 
 		const fileperm = 0o644
 
@@ -263,37 +388,17 @@ func relative(srcname string, outname string) string {
 	return relname
 }
 
-func runRequires(task *task.Task, cmd *cobra.Command) error {
-	for _, req := range task.Requires {
-		rcmd, rargs, err := cmd.Root().Find(req)
-		if err != nil {
-			return err
-		}
-
-		if err := rcmd.RunE(rcmd, rargs); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func addCommands(cmd *cobra.Command, env environ.Environ, filename string, dir string) error {
-	taskdefs, err := os.ReadFile(filepath.Clean(filename))
+func addCommands(cmd *cobra.Command, exec *executor, filename string) error {
+	tasks, err := loadTasks(filename)
 	if err != nil {
 		return err
 	}
 
-	tasks, err := task.Load(taskdefs)
-	if err != nil {
-		return err
-	}
+	exec.tasks = tasks
 
-	if len(tasks) == 0 {
-		return fmt.Errorf("%w in %s", errNoTasks, filename)
-	}
+	addGroups(cmd, tasks)
 
-	for _, task := range tasks {
+	for _, task := range commandTasks(tasks) {
 		sub := &cobra.Command{
 			Use:                task.Name,
 			Short:              task.Short,
@@ -301,25 +406,113 @@ func addCommands(cmd *cobra.Command, env environ.Environ, filename string, dir s
 			FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
 		}
 
-		if len(task.Script) != 0 || len(task.Requires) != 0 {
+		if len(task.Steps) != 0 || len(task.Requires) != 0 {
 			sub.RunE = func(cmd *cobra.Command, args []string) error {
-				if err := runRequires(task, cmd); err != nil {
-					return err
-				}
+				args = append(task.FlagArgs(cmd.Flags()), args...)
+				exec.stdin = cmd.InOrStdin()
+				exec.stdout = cmd.OutOrStdout()
+				exec.stderr = cmd.ErrOrStderr()
 
-				if len(task.Script) != 0 {
-					return shell.Run(cmd.Name(), args, task.Script, dir, env)
+				targets, err := exec.sequence(cmd.Root().PersistentFlags(), append([]string{task.Name}, args...))
+				if err != nil {
+					return err
 				}
 
-				return nil
+				return exec.start(cmd.Context(), targets)
 			}
 		}
 
+		task.AddFlags(sub.Flags())
 		sub.Flags().BoolP("help", "h", false, "Print usage")
 
+		registerTaskCompletions(sub, task)
+
+		if cmd.ContainsGroup(task.Namespace) {
+			sub.GroupID = task.Namespace
+		} else if cmd.ContainsGroup(rootGroup) {
+			sub.GroupID = rootGroup
+		}
+
 		cmd.AddCommand(sub)
 
-		sub.Long = strings.Replace(sub.Long, task.Name, sub.CommandPath(), 1)
+		// the long description starts with the heading, which contains the name without namespace
+		sub.Long = strings.Replace(sub.Long, localName(task), sub.CommandPath(), 1)
+	}
+
+	return nil
+}
+
+// addGroups adds a command group for every namespace (and one for the tasks without namespace),
+// so the tasks are listed by namespace. The title of the group is the short description of the parent task.
+func addGroups(cmd *cobra.Command, tasks map[string]*task.Task) {
+	namespaces := namespaceSet(tasks)
+
+	if len(namespaces) == 0 {
+		return
+	}
+
+	cmd.AddGroup(&cobra.Group{ID: rootGroup, Title: "Tasks:"})
+	cmd.SetHelpCommandGroupID(rootGroup)
+
+	for _, namespace := range sortedKeys(namespaces) {
+		title := namespace
+		if parent, has := tasks[namespace]; has && len(parent.Short) != 0 {
+			title = parent.Short
+		}
+
+		cmd.AddGroup(&cobra.Group{ID: namespace, Title: title + ":"})
+	}
+}
+
+func namespaceSet(tasks map[string]*task.Task) map[string]struct{} {
+	namespaces := make(map[string]struct{})
+
+	for _, task := range tasks {
+		if len(task.Namespace) != 0 {
+			namespaces[task.Namespace] = struct{}{}
+		}
+	}
+
+	return namespaces
+}
+
+// commandTasks returns the tasks available as commands, sorted by name. The parent task of a namespace
+// without code blocks and dependencies only gives the title of the namespace, so it is not a command.
+func commandTasks(tasks map[string]*task.Task) []*task.Task {
+	namespaces := namespaceSet(tasks)
+
+	return slices.DeleteFunc(sortedTasks(tasks), func(t *task.Task) bool {
+		_, has := namespaces[t.Name]
+
+		return has && len(t.Steps) == 0 && len(t.Requires) == 0
+	})
+}
+
+// localName returns the name of the task without the namespace.
+func localName(t *task.Task) string {
+	return strings.TrimPrefix(t.Name, t.Namespace+task.NamespaceSeparator)
+}
+
+// rootGroup is the group of the tasks without namespace, the separator is not a valid namespace.
+const rootGroup = task.NamespaceSeparator
+
+func sortedKeys(set map[string]struct{}) []string {
+	all := make([]string, 0, len(set))
+
+	for key := range set {
+		all = append(all, key)
+	}
+
+	sort.Strings(all)
+
+	return all
+}
+
+func checkUtils(utils []string) error {
+	for _, source := range utils {
+		if source != shell.UtilsBusybox && source != shell.UtilsNative && source != shell.UtilsBuiltin {
+			return fmt.Errorf("%w: %s", errUnknownUtils, source)
+		}
 	}
 
 	return nil
@@ -328,6 +521,11 @@ func addCommands(cmd *cobra.Command, env environ.Environ, filename string, dir s
 var (
 	errNoTasks = errors.New("no task definitions")
 	errNoFile  = errors.New("no task definition file found, use the --file flag to specify one")
+
+	errUnknownFormat = errors.New("unknown format")
+	errUnknownShell  = errors.New("unknown shell")
+	errUnknownUtils  = errors.New("unknown utilities (busybox, native or builtin)")
+	errTimeout       = errors.New("timeout exceeded")
 )
 
 const usageTemplate = `Usage:{{if .Runnable}}
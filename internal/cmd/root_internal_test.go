@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+func TestReservedParams(t *testing.T) {
+	t.Parallel()
+
+	root, err := New([]string{"--version"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		taskdefs := fmt.Sprintf("# Tasks\n\n## run - Run\n\nParams\n: %s\n\n```bash\necho\n```\n", flag.Name)
+
+		if _, err := task.Load([]byte(taskdefs)); err == nil {
+			t.Errorf("parameter %s collides with the flag of the same name", flag.Name)
+		}
+	})
+}
+
+func TestCommandTasks(t *testing.T) {
+	t.Parallel()
+
+	taskdefs := "# Tasks\n\n## tools - Tools\n\nNamespace\n: true\n\n### lint - Lint\n\n```bash\necho\n```\n\n" +
+		"## ci - CI\n\nNamespace\n: true\n\nRequires\n: ci:test\n\n### test - Test\n\n```bash\necho\n```\n\n" +
+		"## docs - Docs\n"
+
+	tasks, err := task.Load([]byte(taskdefs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(tasks))
+	for _, t := range commandTasks(tasks) {
+		names = append(names, t.Name)
+	}
+
+	// the namespace without code blocks and dependencies is not a command, the plain task without them is
+	if want := []string{"ci", "ci:test", "docs", "tools:lint"}; !slices.Equal(names, want) {
+		t.Errorf("commandTasks() = %v, want %v", names, want)
+	}
+}
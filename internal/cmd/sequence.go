@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+)
+
+// sequenceSeparator separates the task invocations on the command line.
+const sequenceSeparator = "+"
+
+// splitSequence splits the command line arguments at the first standalone separator.
+// The rest of the arguments (the following task invocations) are returned split at the separators.
+// A separator after the "--" argument is a task argument.
+func splitSequence(args []string) ([]string, [][]string) {
+	var (
+		head []string
+		rest [][]string
+	)
+
+	for idx, arg := range args {
+		if arg == "--" && rest == nil {
+			return args, nil
+		}
+
+		if arg != sequenceSeparator {
+			if rest == nil {
+				continue
+			}
+
+			rest[len(rest)-1] = append(rest[len(rest)-1], arg)
+
+			continue
+		}
+
+		if rest == nil {
+			head = args[:idx]
+		}
+
+		rest = append(rest, nil)
+	}
+
+	if rest == nil {
+		return args, nil
+	}
+
+	return head, rest
+}
+
+// sequence returns the task invocations to run: the first one followed by the ones given after the separators.
+// Cobra parses the flags of the first task only, the flags of the following tasks are parsed here.
+func (e *executor) sequence(flags *pflag.FlagSet, first []string) ([][]string, error) {
+	targets := [][]string{first}
+
+	for _, target := range e.then {
+		if len(target) == 0 {
+			return nil, fmt.Errorf("%w after %s", errNoTaskName, sequenceSeparator)
+		}
+
+		task, found := e.tasks[target[0]]
+		if !found {
+			// the missing task is reported by the resolution
+			targets = append(targets, target)
+
+			continue
+		}
+
+		tflags := pflag.NewFlagSet(task.Name, pflag.ContinueOnError)
+		tflags.ParseErrorsWhitelist = pflag.ParseErrorsWhitelist{UnknownFlags: true}
+		tflags.SetOutput(io.Discard)
+
+		task.AddFlags(tflags)
+		tflags.AddFlagSet(flags)
+
+		if err := tflags.Parse(target[1:]); err != nil && !errors.Is(err, pflag.ErrHelp) {
+			return nil, fmt.Errorf("%s: %w", task.Name, err)
+		}
+
+		targets = append(targets, append(append([]string{task.Name}, task.FlagArgs(tflags)...), tflags.Args()...))
+	}
+
+	return targets, nil
+}
@@ -21,7 +21,7 @@ func token2flag(src []string, fenv, ffile *pflag.Flag) []string {
 		} else {
 			if arg == short(ffile) || arg == long(ffile) {
 				isFile = true
-			} else if arg[0] == '@' {
+			} else if strings.HasPrefix(arg, "@") {
 				args = append(args, long(ffile), arg[1:])
 
 				continue
@@ -33,7 +33,7 @@ func token2flag(src []string, fenv, ffile *pflag.Flag) []string {
 		} else {
 			if arg == short(fenv) || arg == long(fenv) {
 				isEnv = true
-			} else if strings.ContainsRune(arg, '=') {
+			} else if strings.ContainsRune(arg, '=') && !strings.HasPrefix(arg, "-") {
 				args = append(args, long(fenv))
 			}
 		}
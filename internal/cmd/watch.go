@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/szkiba/cdo/internal/glob"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+// watchInterval is the polling interval of the watched files. A change is acted upon
+// only after the files have been unchanged for an interval (debouncing).
+const watchInterval = 500 * time.Millisecond
+
+// watchSet contains the watched patterns of a directory. The generated files are not watched,
+// otherwise a task generating one of its sources would be restarted forever.
+type watchSet struct {
+	dir       string
+	patterns  []string
+	generated []*regexp.Regexp
+}
+
+// fileState is the size and the modification time of a watched file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watch runs the tasks, then reruns them whenever the watched files change.
+// The running tasks are canceled when a change is detected.
+func (e *executor) watch(ctx context.Context, targets [][]string) error {
+	sets, err := e.watchSets(targets)
+	if err != nil {
+		return err
+	}
+
+	for {
+		state, err := snapshot(sets)
+		if err != nil {
+			return err
+		}
+
+		changed, err := e.watchRun(ctx, targets, sets, state)
+		if err != nil {
+			return err
+		}
+
+		if rel, err := filepath.Rel(e.dir, changed); err == nil {
+			changed = rel
+		}
+
+		fmt.Fprintf(e.stderr, "%s changed, restarting\n", changed)
+	}
+}
+
+// watchRun runs the tasks and waits for a change of the watched files, which is returned.
+func (e *executor) watchRun(
+	ctx context.Context, targets [][]string, sets []*watchSet, state map[string]fileState,
+) (string, error) {
+	runctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() { done <- e.run(runctx, targets) }()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	running := true
+	changed := ""
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+
+			if running {
+				<-done
+			}
+
+			return "", context.Cause(ctx)
+		case err := <-done:
+			running = false
+
+			if err != nil {
+				fmt.Fprintln(e.stderr, "Error:", err)
+			}
+
+			fmt.Fprintln(e.stderr, "watching for changes, press Ctrl+C to stop")
+		case <-ticker.C:
+			next, err := snapshot(sets)
+			if err != nil {
+				return "", err
+			}
+
+			if name := diff(state, next); len(name) != 0 {
+				state, changed = next, name
+
+				continue
+			}
+
+			if len(changed) != 0 {
+				cancel()
+
+				if running {
+					<-done
+				}
+
+				return changed, nil
+			}
+		}
+	}
+}
+
+// watchSets returns the patterns to watch: the --watch-glob patterns or the sources of the tasks to be executed.
+func (e *executor) watchSets(targets [][]string) ([]*watchSet, error) {
+	nodes, err := task.ResolveAll(e.tasks, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make(map[string]*watchSet)
+
+	get := func(dir string) *watchSet {
+		if set, has := sets[dir]; has {
+			return set
+		}
+
+		set := &watchSet{dir: dir}
+		sets[dir] = set
+
+		return set
+	}
+
+	if len(e.watchGlobs) != 0 {
+		get(e.dir).patterns = e.watchGlobs
+	}
+
+	for _, node := range nodes {
+		set := get(e.taskDir(node.Task))
+
+		if len(e.watchGlobs) == 0 {
+			set.patterns = append(set.patterns, node.Task.Sources...)
+		}
+
+		for _, pattern := range node.Task.Generates {
+			re, err := glob.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			set.generated = append(set.generated, re)
+		}
+	}
+
+	all := make([]*watchSet, 0, len(sets))
+
+	for _, set := range sets {
+		if len(set.patterns) != 0 {
+			all = append(all, set)
+		}
+	}
+
+	if len(all) == 0 {
+		return nil, errNothingToWatch
+	}
+
+	return all, nil
+}
+
+func snapshot(sets []*watchSet) (map[string]fileState, error) {
+	state := make(map[string]fileState)
+
+	for _, set := range sets {
+		files, err := glob.Files(set.dir, set.patterns)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if set.isGenerated(file) {
+				continue
+			}
+
+			name := filepath.Join(set.dir, filepath.FromSlash(file))
+
+			info, err := os.Stat(name)
+			if err != nil {
+				// the file has been removed since globbing, it is not in the snapshot
+				continue
+			}
+
+			state[name] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+
+	return state, nil
+}
+
+func (s *watchSet) isGenerated(file string) bool {
+	for _, re := range s.generated {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diff returns the first (in lexical order) created, modified or removed file.
+func diff(prev, next map[string]fileState) string {
+	var changed []string
+
+	for name, state := range next {
+		if old, has := prev[name]; !has || old.size != state.size || !old.modTime.Equal(state.modTime) {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range prev {
+		if _, has := next[name]; !has {
+			changed = append(changed, name)
+		}
+	}
+
+	if len(changed) == 0 {
+		return ""
+	}
+
+	sort.Strings(changed)
+
+	return changed[0]
+}
+
+var errNothingToWatch = errors.New("nothing to watch, declare the Sources of the task or use the --watch-glob flag")
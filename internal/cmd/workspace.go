@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/szkiba/cdo/internal/environ"
+	"github.com/szkiba/cdo/internal/task"
+	"github.com/szkiba/cdo/internal/workspace"
+)
+
+// projectResult is the outcome of running the task in a project.
+type projectResult struct {
+	project  *workspace.Project
+	err      error
+	duration time.Duration
+}
+
+// runRecursive runs the task in every project of the workspace (the directory of the task definition file
+// and its subdirectories) that defines it. A failing project does not stop the others, a summary is printed at the end.
+func runRecursive(filename, dir string, exec *executor, flagenv environ.Environ) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errNoTaskName
+		}
+
+		name := args[0]
+
+		ctx, cancel := exec.deadline(cmd.Context(), []string{name})
+		defer cancel()
+
+		projects, err := workspace.Discover(dir)
+		if err != nil {
+			return err
+		}
+
+		projects = append([]*workspace.Project{{Path: ".", Dir: dir, File: filename}}, projects...)
+
+		var results []*projectResult
+
+		for _, project := range projects {
+			if ctx.Err() != nil {
+				break
+			}
+
+			tasks, err := task.LoadFile(project.File)
+			if err == nil {
+				if _, has := tasks[name]; !has {
+					continue
+				}
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "==> %s\n", project.Path)
+
+			start := time.Now()
+
+			if err == nil {
+				err = runProject(ctx, cmd, exec, flagenv, project, tasks, args)
+			}
+
+			results = append(results, &projectResult{project: project, err: err, duration: time.Since(start)})
+		}
+
+		if len(results) == 0 {
+			return fmt.Errorf("%w: %s", errNoProject, name)
+		}
+
+		err = summarize(cmd, results)
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		return err
+	}
+}
+
+func runProject(
+	ctx context.Context,
+	cmd *cobra.Command,
+	exec *executor,
+	flagenv environ.Environ,
+	project *workspace.Project,
+	tasks map[string]*task.Task,
+	args []string,
+) error {
+	env := maps.Clone(exec.env)
+	if err := env.Load(project.Dir); err != nil {
+		return err
+	}
+
+	env.Override(flagenv)
+
+	pexec := *exec
+	pexec.tasks = tasks
+	pexec.dir = project.Dir
+	pexec.env = env
+	pexec.stdin = cmd.InOrStdin()
+	pexec.stdout = cmd.OutOrStdout()
+	pexec.stderr = cmd.ErrOrStderr()
+
+	targets, err := pexec.sequence(cmd.Root().PersistentFlags(), args)
+	if err != nil {
+		return err
+	}
+
+	return pexec.run(ctx, targets)
+}
+
+// summarize prints the result of every project and returns an error if any of them failed.
+func summarize(cmd *cobra.Command, results []*projectResult) error {
+	out := tabwriter.NewWriter(cmd.ErrOrStderr(), 0, 0, 2, ' ', 0) //nolint:mnd
+
+	fmt.Fprintln(out)
+
+	failed := 0
+
+	for _, res := range results {
+		status, msg := "ok", ""
+
+		if res.err != nil {
+			failed++
+			status, msg = "FAIL", res.err.Error()
+		}
+
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", status, res.project.Path, res.duration.Round(time.Millisecond), msg)
+	}
+
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d of %d %w", failed, len(results), errProjectsFailed)
+	}
+
+	return nil
+}
+
+// lookupProject returns the project and the task name of a target given as project path and task name
+// separated by colon (for example services/api:test). Task names of the current task definition file
+// take precedence, so namespaced tasks are not mistaken for projects.
+func lookupProject(filename, dir, target string) (*workspace.Project, string, bool) {
+	path, name, found := strings.Cut(target, task.NamespaceSeparator)
+	if !found || len(path) == 0 || len(name) == 0 {
+		return nil, "", false
+	}
+
+	if tasks, err := task.LoadFile(filename); err == nil {
+		if _, has := tasks[target]; has {
+			return nil, "", false
+		}
+	}
+
+	project, found := workspace.Lookup(dir, path)
+	if !found {
+		return nil, "", false
+	}
+
+	return project, name, true
+}
+
+// replaceArg replaces the first occurrence of the argument.
+func replaceArg(args []string, from, to string) []string {
+	args = append([]string{}, args...)
+
+	for idx, arg := range args {
+		if arg == from {
+			args[idx] = to
+
+			break
+		}
+	}
+
+	return args
+}
+
+var (
+	errNoTaskName     = errors.New("task name required")
+	errNoProject      = errors.New("no project defines the task")
+	errProjectsFailed = errors.New("project(s) failed")
+)
@@ -0,0 +1,210 @@
+// Package coreutils implements portable versions of the most common core utilities.
+//
+// Like busybox, the utilities support the frequently used subset of the options,
+// so the tasks using them work the same way on every platform without installing anything.
+package coreutils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Env is the environment of a utility.
+type Env struct {
+	// Dir is the working directory, relative paths are resolved against it.
+	Dir    string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Exec executes a command (used by the utilities executing other commands, such as xargs).
+	Exec func(ctx context.Context, args []string) error
+}
+
+// Command is a utility. The arguments don't contain the name of the utility.
+// A Status error is an exit status without message, other errors are reported with exit status 1.
+type Command func(ctx context.Context, env *Env, args []string) error
+
+// Status is an exit status without error message (for example grep without matching lines).
+type Status uint8
+
+func (s Status) Error() string {
+	return fmt.Sprintf("exit status %d", s)
+}
+
+// Lookup returns the utility with the given name.
+func Lookup(name string) (Command, bool) {
+	cmd, found := commands()[name]
+
+	return cmd, found
+}
+
+// Names returns the sorted names of the utilities.
+func Names() []string {
+	all := commands()
+	names := make([]string, 0, len(all))
+
+	for name := range all {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func commands() map[string]Command {
+	return map[string]Command{
+		"basename":  basename,
+		"cat":       cat,
+		"cp":        cp,
+		"dirname":   dirname,
+		"find":      find,
+		"grep":      grep,
+		"head":      head,
+		"ls":        ls,
+		"mkdir":     mkdir,
+		"mv":        mv,
+		"rm":        rm,
+		"sed":       sed,
+		"sha256sum": sha256sum,
+		"sort":      sortCmd,
+		"tail":      tail,
+		"touch":     touch,
+		"tr":        tr,
+		"uniq":      uniq,
+		"wc":        wc,
+		"xargs":     xargs,
+	}
+}
+
+func newFlags(name string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+
+	flags.SetOutput(io.Discard)
+	flags.SortFlags = false
+
+	return flags
+}
+
+// path returns the file name resolved against the working directory.
+func (e *Env) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(e.Dir, name)
+}
+
+// open opens the input file, "-" is the standard input.
+func (e *Env) open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(e.Stdin), nil
+	}
+
+	return os.Open(e.path(name))
+}
+
+// eachInput calls fn with every input file (the standard input if there is no file).
+func (e *Env) eachInput(files []string, fn func(name string, input io.Reader) error) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if err := e.withInput(name, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Env) withInput(name string, fn func(name string, input io.Reader) error) error {
+	input, err := e.open(name)
+	if err != nil {
+		return err
+	}
+
+	defer input.Close() //nolint:errcheck
+
+	return fn(name, input)
+}
+
+// eachLine calls fn with every line of the input, without the line terminator.
+func eachLine(input io.Reader, fn func(line string) error) error {
+	reader := bufio.NewReader(input)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) != 0 {
+			if ferr := fn(strings.TrimSuffix(line, "\n")); ferr != nil {
+				return ferr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLines returns the lines of all input files.
+func (e *Env) readLines(files []string) ([]string, error) {
+	var lines []string
+
+	err := e.eachInput(files, func(_ string, input io.Reader) error {
+		return eachLine(input, func(line string) error {
+			lines = append(lines, line)
+
+			return nil
+		})
+	})
+
+	return lines, err
+}
+
+// legacyCount converts the obsolete -N count option (head -5) to -n N.
+func legacyCount(args []string) []string {
+	result := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		if len(arg) > 1 && arg[0] == '-' && isDigits(arg[1:]) {
+			result = append(result, "-n", arg[1:])
+		} else {
+			result = append(result, arg)
+		}
+	}
+
+	return result
+}
+
+func isDigits(str string) bool {
+	for _, char := range str {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return len(str) != 0
+}
+
+var (
+	errMissingOperand = errors.New("missing operand")
+	errExtraOperand   = errors.New("extra operand")
+	errIsDirectory    = errors.New("is a directory")
+	errNotDirectory   = errors.New("target is not a directory")
+	errInvalidNumber  = errors.New("invalid number")
+)
@@ -0,0 +1,76 @@
+package coreutils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run executes the utility in the directory with the given standard input and returns the standard output.
+func run(t *testing.T, dir string, cmd Command, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	env := &Env{
+		Dir:    dir,
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Exec: func(_ context.Context, args []string) error {
+			stdout.WriteString(strings.Join(args, " ") + "\n")
+
+			return nil
+		},
+	}
+
+	err := cmd(context.Background(), env, args)
+
+	return stdout.String(), err
+}
+
+// tree creates the files (and their parent directories) in a temporary directory, names ending with / are directories.
+func tree(t *testing.T, names ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range Names() {
+		if _, found := Lookup(name); !found {
+			t.Errorf("Lookup(%q) not found", name)
+		}
+	}
+
+	if _, found := Lookup("awk"); found {
+		t.Error("Lookup(awk) found")
+	}
+}
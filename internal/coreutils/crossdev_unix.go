@@ -0,0 +1,13 @@
+//go:build !windows
+
+package coreutils
+
+import (
+	"errors"
+	"syscall"
+)
+
+// crossDevice reports whether the rename failed because the source and the destination are on different file systems.
+func crossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package coreutils
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorNotSameDevice is the ERROR_NOT_SAME_DEVICE Windows error code.
+const errorNotSameDevice = syscall.Errno(17)
+
+// crossDevice reports whether the rename failed because the source and the destination are on different volumes.
+func crossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
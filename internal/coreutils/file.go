@@ -0,0 +1,387 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+func cat(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("cat")
+	number := flags.BoolP("number", "n", false, "number the lines")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	count := 0
+
+	return env.eachInput(flags.Args(), func(_ string, input io.Reader) error {
+		if !*number {
+			_, err := io.Copy(env.Stdout, input)
+
+			return err
+		}
+
+		return eachLine(input, func(line string) error {
+			count++
+
+			_, err := fmt.Fprintf(env.Stdout, "%6d\t%s\n", count, line)
+
+			return err
+		})
+	})
+}
+
+func cp(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("cp")
+	recursive := flags.BoolP("recursive", "r", false, "copy directories recursively")
+	flags.BoolP("Recursive", "R", false, "copy directories recursively")
+	flags.BoolP("force", "f", false, "ignored")
+	preserve := flags.BoolP("preserve", "p", false, "preserve the modification time")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	*recursive = *recursive || flags.Lookup("Recursive").Changed
+
+	return transfer(env, flags.Args(), func(src, dst string) error {
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && !*recursive {
+			return fmt.Errorf("%w (not copied without -r): %s", errIsDirectory, src)
+		}
+
+		return copyTree(src, dst, *preserve)
+	})
+}
+
+func mv(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("mv")
+	flags.BoolP("force", "f", false, "ignored")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return transfer(env, flags.Args(), func(src, dst string) error {
+		err := os.Rename(src, dst)
+		if err == nil || !crossDevice(err) {
+			return err
+		}
+
+		// the rename fails between file systems
+		if err := copyTree(src, dst, true); err != nil {
+			return err
+		}
+
+		return os.RemoveAll(src)
+	})
+}
+
+// transfer calls fn for the sources and the destination (the last argument).
+// If the destination is a directory, the sources are transferred into it.
+func transfer(env *Env, args []string, fn func(src, dst string) error) error {
+	if len(args) < 2 { //nolint:mnd
+		return errMissingOperand
+	}
+
+	srcs, dst := args[:len(args)-1], env.path(args[len(args)-1])
+
+	info, err := os.Stat(dst)
+	isDir := err == nil && info.IsDir()
+
+	if len(srcs) > 1 && !isDir {
+		return fmt.Errorf("%w: %s", errNotDirectory, args[len(args)-1])
+	}
+
+	for _, src := range srcs {
+		target := dst
+		if isDir {
+			target = filepath.Join(dst, filepath.Base(src))
+		}
+
+		if err := fn(env.path(src), target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyTree(src, dst string, preserve bool) error {
+	return filepath.WalkDir(src, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, name)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+
+		if err := copyFile(name, target, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		if preserve {
+			return os.Chtimes(target, info.ModTime(), info.ModTime())
+		}
+
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.OpenFile(filepath.Clean(dst), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close() //nolint:errcheck,gosec
+
+		return err
+	}
+
+	return out.Close()
+}
+
+func rm(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("rm")
+	recursive := flags.BoolP("recursive", "r", false, "remove directories recursively")
+	flags.BoolP("Recursive", "R", false, "remove directories recursively")
+	force := flags.BoolP("force", "f", false, "ignore nonexistent files")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	*recursive = *recursive || flags.Lookup("Recursive").Changed
+
+	if flags.NArg() == 0 && !*force {
+		return errMissingOperand
+	}
+
+	for _, arg := range flags.Args() {
+		name := env.path(arg)
+
+		info, err := os.Lstat(name)
+		if err != nil {
+			if *force && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return err
+		}
+
+		if info.IsDir() && !*recursive {
+			return fmt.Errorf("%w: %s", errIsDirectory, arg)
+		}
+
+		if err := os.RemoveAll(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mkdir(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("mkdir")
+	parents := flags.BoolP("parents", "p", false, "make parent directories as needed")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errMissingOperand
+	}
+
+	const perm = 0o755
+
+	for _, arg := range flags.Args() {
+		var err error
+
+		if *parents {
+			err = os.MkdirAll(env.path(arg), perm)
+		} else {
+			err = os.Mkdir(env.path(arg), perm)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func touch(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("touch")
+	nocreate := flags.BoolP("no-create", "c", false, "do not create files")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errMissingOperand
+	}
+
+	now := time.Now()
+
+	for _, arg := range flags.Args() {
+		name := env.path(arg)
+
+		err := os.Chtimes(name, now, now)
+		if err == nil || (*nocreate && errors.Is(err, os.ErrNotExist)) {
+			continue
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		const perm = 0o644
+
+		file, err := os.OpenFile(filepath.Clean(name), os.O_WRONLY|os.O_CREATE, perm)
+		if err != nil {
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ls(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("ls")
+	all := flags.BoolP("all", "a", false, "do not ignore entries starting with .")
+	long := flags.BoolP("long", "l", false, "use a long listing format")
+	directory := flags.BoolP("directory", "d", false, "list directories themselves")
+	flags.BoolP("one", "1", false, "list one file per line")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	names := flags.Args()
+	if len(names) == 0 {
+		names = []string{"."}
+	}
+
+	list := &lister{env: env, long: *long}
+
+	var dirs []string
+
+	for _, name := range names {
+		info, err := os.Stat(env.path(name))
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && !*directory {
+			dirs = append(dirs, name)
+
+			continue
+		}
+
+		if err := list.entry(name, info); err != nil {
+			return err
+		}
+	}
+
+	for idx, dir := range dirs {
+		if len(names) > 1 {
+			if idx > 0 || len(dirs) < len(names) {
+				fmt.Fprintln(env.Stdout)
+			}
+
+			fmt.Fprintf(env.Stdout, "%s:\n", dir)
+		}
+
+		if err := list.dir(dir, *all); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type lister struct {
+	env  *Env
+	long bool
+}
+
+func (l *lister) dir(dir string, all bool) error {
+	entries, err := os.ReadDir(l.env.path(dir))
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		if !all && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if err := l.entry(entry.Name(), info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *lister) entry(name string, info fs.FileInfo) error {
+	var err error
+
+	if l.long {
+		_, err = fmt.Fprintf(l.env.Stdout, "%s %10d %s %s\n",
+			info.Mode(), info.Size(), info.ModTime().Format("Jan _2 15:04"), name)
+	} else {
+		_, err = fmt.Fprintln(l.env.Stdout, name)
+	}
+
+	return err
+}
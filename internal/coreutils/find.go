@@ -0,0 +1,548 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// findEntry is a file visited by find.
+type findEntry struct {
+	// path is the path as printed (starting with the starting point)
+	path  string
+	name  string
+	abs   string
+	depth int
+	entry fs.DirEntry
+}
+
+type findPredicate func(ctx context.Context, entry *findEntry) (bool, error)
+
+// finder contains the parsed expression of find. The supported tests are -name, -iname, -path, -type, -empty,
+// -newer, the actions are -print, -print0, -delete and -exec,
+// the operators are !, -not, -a, -and, -o, -or and parentheses.
+type finder struct {
+	env      *Env
+	args     []string
+	pos      int
+	minDepth int
+	maxDepth int
+	action   bool
+	delete   bool
+	// batches contains the file names of the -exec ... {} + actions
+	batches []*findBatch
+}
+
+type findBatch struct {
+	args  []string
+	names []string
+}
+
+func find(ctx context.Context, env *Env, args []string) error {
+	var roots []string
+
+	for len(args) != 0 && !strings.HasPrefix(args[0], "-") && args[0] != "!" && args[0] != "(" {
+		roots, args = append(roots, args[0]), args[1:]
+	}
+
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	fnd := &finder{env: env, args: args, maxDepth: -1}
+
+	expr, err := fnd.parse()
+	if err != nil {
+		return err
+	}
+
+	if !fnd.action {
+		test := expr
+		expr = func(ctx context.Context, entry *findEntry) (bool, error) {
+			matched, err := test(ctx, entry)
+			if err != nil || !matched {
+				return false, err
+			}
+
+			return fnd.print(entry, "\n")
+		}
+	}
+
+	for _, root := range roots {
+		if err := fnd.walk(ctx, root, expr); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range fnd.batches {
+		if len(batch.names) != 0 {
+			if err := env.Exec(ctx, substitute(batch.args, batch.names)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *finder) walk(ctx context.Context, root string, expr findPredicate) error {
+	var entries []*findEntry
+
+	base := f.env.path(root)
+
+	err := filepath.WalkDir(base, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(base, name)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		item := &findEntry{path: root, name: entry.Name(), abs: name, entry: entry}
+
+		if rel != "." {
+			item.path = strings.TrimSuffix(root, "/") + "/" + rel
+			item.depth = strings.Count(rel, "/") + 1
+		} else {
+			item.name = path.Base(filepath.ToSlash(root))
+		}
+
+		if f.maxDepth >= 0 && item.depth > f.maxDepth {
+			return fs.SkipDir
+		}
+
+		entries = append(entries, item)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// -delete processes the contents of the directories before the directories
+	if f.delete {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.depth < f.minDepth {
+			continue
+		}
+
+		if _, err := expr(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *finder) parse() (findPredicate, error) {
+	if len(f.args) == 0 {
+		return func(context.Context, *findEntry) (bool, error) { return true, nil }, nil
+	}
+
+	expr, err := f.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.pos < len(f.args) {
+		return nil, fmt.Errorf("%w: %s", errFindExpression, f.args[f.pos])
+	}
+
+	return expr, nil
+}
+
+func (f *finder) peek() string {
+	if f.pos < len(f.args) {
+		return f.args[f.pos]
+	}
+
+	return ""
+}
+
+func (f *finder) or() (findPredicate, error) {
+	left, err := f.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for f.peek() == "-o" || f.peek() == "-or" {
+		f.pos++
+
+		right, err := f.and()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orPredicate(left, right)
+	}
+
+	return left, nil
+}
+
+func orPredicate(left, right findPredicate) findPredicate {
+	return func(ctx context.Context, entry *findEntry) (bool, error) {
+		matched, err := left(ctx, entry)
+		if err != nil || matched {
+			return matched, err
+		}
+
+		return right(ctx, entry)
+	}
+}
+
+func (f *finder) and() (findPredicate, error) {
+	left, err := f.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		next := f.peek()
+		if len(next) == 0 || next == "-o" || next == "-or" || next == ")" {
+			return left, nil
+		}
+
+		if next == "-a" || next == "-and" {
+			f.pos++
+		}
+
+		right, err := f.not()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andPredicate(left, right)
+	}
+}
+
+func andPredicate(left, right findPredicate) findPredicate {
+	return func(ctx context.Context, entry *findEntry) (bool, error) {
+		matched, err := left(ctx, entry)
+		if err != nil || !matched {
+			return false, err
+		}
+
+		return right(ctx, entry)
+	}
+}
+
+func (f *finder) not() (findPredicate, error) {
+	if f.peek() != "!" && f.peek() != "-not" {
+		return f.primary()
+	}
+
+	f.pos++
+
+	expr, err := f.not()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, entry *findEntry) (bool, error) {
+		matched, err := expr(ctx, entry)
+
+		return !matched, err
+	}, nil
+}
+
+// operand returns the operand of the primary.
+func (f *finder) operand(primary string) (string, error) {
+	if f.pos >= len(f.args) {
+		return "", fmt.Errorf("%w: %s", errFindOperand, primary)
+	}
+
+	f.pos++
+
+	return f.args[f.pos-1], nil
+}
+
+//nolint:cyclop,funlen
+func (f *finder) primary() (findPredicate, error) {
+	primary, err := f.operand("expression")
+	if err != nil {
+		return nil, err
+	}
+
+	always := func(context.Context, *findEntry) (bool, error) { return true, nil }
+
+	switch primary {
+	case "(":
+		expr, err := f.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if f.peek() != ")" {
+			return nil, fmt.Errorf("%w: missing )", errFindExpression)
+		}
+
+		f.pos++
+
+		return expr, nil
+	case "-name", "-iname":
+		pattern, err := f.operand(primary)
+		if err != nil {
+			return nil, err
+		}
+
+		fold := primary == "-iname"
+
+		return f.matcher(pattern, fold, func(entry *findEntry) string { return entry.name })
+	case "-path", "-wholename":
+		pattern, err := f.operand(primary)
+		if err != nil {
+			return nil, err
+		}
+
+		return f.matcher(pattern, false, func(entry *findEntry) string { return entry.path })
+	case "-type":
+		kind, err := f.operand(primary)
+		if err != nil {
+			return nil, err
+		}
+
+		return typePredicate(kind)
+	case "-empty":
+		return emptyPredicate, nil
+	case "-newer":
+		name, err := f.operand(primary)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(f.env.path(name))
+		if err != nil {
+			return nil, err
+		}
+
+		return newerPredicate(info.ModTime()), nil
+	case "-maxdepth", "-mindepth":
+		value, err := f.operand(primary)
+		if err != nil {
+			return nil, err
+		}
+
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			return nil, fmt.Errorf("%w: %s %s", errInvalidNumber, primary, value)
+		}
+
+		if primary == "-maxdepth" {
+			f.maxDepth = depth
+		} else {
+			f.minDepth = depth
+		}
+
+		return always, nil
+	case "-print", "-print0":
+		f.action = true
+
+		terminator := "\n"
+		if primary == "-print0" {
+			terminator = "\x00"
+		}
+
+		return func(_ context.Context, entry *findEntry) (bool, error) { return f.print(entry, terminator) }, nil
+	case "-delete":
+		f.action, f.delete = true, true
+
+		return func(_ context.Context, entry *findEntry) (bool, error) {
+			if entry.depth == 0 && entry.path == "." {
+				return true, nil
+			}
+
+			return true, os.Remove(entry.abs)
+		}, nil
+	case "-exec":
+		f.action = true
+
+		return f.exec()
+	default:
+		return nil, fmt.Errorf("%w: %s", errFindExpression, primary)
+	}
+}
+
+func (f *finder) print(entry *findEntry, terminator string) (bool, error) {
+	_, err := fmt.Fprint(f.env.Stdout, entry.path, terminator)
+
+	return true, err
+}
+
+func (f *finder) matcher(pattern string, fold bool, subject func(*findEntry) string) (findPredicate, error) {
+	re, err := fnmatch(pattern, fold)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(_ context.Context, entry *findEntry) (bool, error) {
+		return re.MatchString(subject(entry)), nil
+	}, nil
+}
+
+// exec parses the -exec action: the command is terminated by ; (executed for every file)
+// or by {} + (executed once with all the file names).
+func (f *finder) exec() (findPredicate, error) {
+	var args []string
+
+	for f.pos < len(f.args) {
+		arg := f.args[f.pos]
+		f.pos++
+
+		if arg == ";" {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("%w: -exec", errFindOperand)
+			}
+
+			return func(ctx context.Context, entry *findEntry) (bool, error) {
+				err := f.env.Exec(ctx, substitute(args, []string{entry.path}))
+				if err != nil && ctx.Err() != nil {
+					return false, err
+				}
+
+				return err == nil, nil
+			}, nil
+		}
+
+		if arg == "+" && len(args) != 0 && args[len(args)-1] == "{}" {
+			batch := &findBatch{args: args}
+			f.batches = append(f.batches, batch)
+
+			return func(_ context.Context, entry *findEntry) (bool, error) {
+				batch.names = append(batch.names, entry.path)
+
+				return true, nil
+			}, nil
+		}
+
+		args = append(args, arg)
+	}
+
+	return nil, fmt.Errorf("%w: -exec", errFindOperand)
+}
+
+// substitute replaces the {} arguments with the names.
+func substitute(args []string, names []string) []string {
+	var result []string
+
+	for _, arg := range args {
+		if arg == "{}" {
+			result = append(result, names...)
+		} else {
+			result = append(result, strings.ReplaceAll(arg, "{}", strings.Join(names, " ")))
+		}
+	}
+
+	return result
+}
+
+func typePredicate(kind string) (findPredicate, error) {
+	var test func(fs.FileMode) bool
+
+	switch kind {
+	case "f":
+		test = fs.FileMode.IsRegular
+	case "d":
+		test = fs.FileMode.IsDir
+	case "l":
+		test = func(mode fs.FileMode) bool { return mode&fs.ModeSymlink != 0 }
+	default:
+		return nil, fmt.Errorf("%w: -type %s", errFindExpression, kind)
+	}
+
+	return func(_ context.Context, entry *findEntry) (bool, error) {
+		return test(entry.entry.Type()), nil
+	}, nil
+}
+
+func emptyPredicate(_ context.Context, entry *findEntry) (bool, error) {
+	if entry.entry.IsDir() {
+		entries, err := os.ReadDir(entry.abs)
+
+		return len(entries) == 0, err
+	}
+
+	info, err := entry.entry.Info()
+	if err != nil {
+		return false, err
+	}
+
+	return info.Mode().IsRegular() && info.Size() == 0, nil
+}
+
+func newerPredicate(reference time.Time) findPredicate {
+	return func(_ context.Context, entry *findEntry) (bool, error) {
+		info, err := entry.entry.Info()
+		if err != nil {
+			return false, err
+		}
+
+		return info.ModTime().After(reference), nil
+	}
+}
+
+// fnmatch converts the shell pattern to regular expression. Unlike in path.Match, * matches / too (as in find -path).
+func fnmatch(pattern string, fold bool) (*regexp.Regexp, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, pattern)
+	}
+
+	var buff strings.Builder
+
+	if fold {
+		buff.WriteString("(?i)")
+	}
+
+	buff.WriteString("^")
+
+	for idx := 0; idx < len(pattern); idx++ {
+		switch char := pattern[idx]; char {
+		case '*':
+			buff.WriteString(".*")
+		case '?':
+			buff.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[idx+1:], ']')
+			class := pattern[idx+1 : idx+1+end]
+
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			buff.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			idx += end + 1
+		case '\\':
+			if idx+1 < len(pattern) {
+				idx++
+				buff.WriteString(regexp.QuoteMeta(pattern[idx : idx+1]))
+			}
+		default:
+			buff.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	buff.WriteString("$")
+
+	return regexp.Compile(buff.String())
+}
+
+var (
+	errFindExpression = errors.New("invalid expression")
+	errFindOperand    = errors.New("missing argument")
+)
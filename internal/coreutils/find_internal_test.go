@@ -0,0 +1,154 @@
+package coreutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	dir := tree(t, "a.go", "b.txt", "sub/c.go", "sub/D.GO", "sub/deep/e.md", "empty/")
+
+	if err := os.WriteFile(filepath.Join(dir, "zero"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"all", []string{"sub"}, "sub\nsub/D.GO\nsub/c.go\nsub/deep\nsub/deep/e.md\n"},
+		{"name", []string{".", "-name", "*.go"}, "./a.go\n./sub/c.go\n"},
+		{"iname", []string{".", "-iname", "*.go"}, "./a.go\n./sub/D.GO\n./sub/c.go\n"},
+		{"path", []string{".", "-path", "./sub/*.md"}, "./sub/deep/e.md\n"},
+		{"type d", []string{".", "-type", "d"}, ".\n./empty\n./sub\n./sub/deep\n"},
+		{"type f", []string{"sub", "-type", "f"}, "sub/D.GO\nsub/c.go\nsub/deep/e.md\n"},
+		{"maxdepth", []string{".", "-maxdepth", "1", "-type", "f"}, "./a.go\n./b.txt\n./zero\n"},
+		{"mindepth", []string{"sub", "-mindepth", "2"}, "sub/deep/e.md\n"},
+		{"not", []string{".", "-type", "f", "!", "-name", "*.go"}, "./b.txt\n./sub/D.GO\n./sub/deep/e.md\n./zero\n"},
+		{"or", []string{".", "-name", "*.md", "-o", "-name", "*.txt"}, "./b.txt\n./sub/deep/e.md\n"},
+		{
+			"parentheses", []string{".", "(", "-name", "*.md", "-o", "-name", "*.txt", ")", "-a", "-path", "./sub/*"},
+			"./sub/deep/e.md\n",
+		},
+		{"empty", []string{".", "-empty"}, "./empty\n./zero\n"},
+		{"print0", []string{"sub/deep", "-print0"}, "sub/deep\x00sub/deep/e.md\x00"},
+		{"action limits printing", []string{".", "-name", "*.md", "-print", "-o", "-name", "a.go"}, "./sub/deep/e.md\n"},
+		{"exec", []string{"sub", "-iname", "*.go", "-exec", "echo", "{}", ";"}, "echo sub/D.GO\necho sub/c.go\n"},
+		{"exec batch", []string{"sub", "-iname", "*.go", "-exec", "echo", "{}", "+"}, "echo sub/D.GO sub/c.go\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := run(t, dir, find, "", tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("find %q = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindNewer(t *testing.T) {
+	t.Parallel()
+
+	dir := tree(t, "old", "new")
+
+	past := time.Now().Add(-time.Hour)
+
+	if err := os.Chtimes(filepath.Join(dir, "old"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := run(t, dir, find, "", ".", "-type", "f", "-newer", "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "./new\n"; got != want {
+		t.Errorf("find -newer = %q, want %q", got, want)
+	}
+}
+
+func TestFindDelete(t *testing.T) {
+	t.Parallel()
+
+	dir := tree(t, "keep.txt", "gen/a.o", "gen/sub/b.o")
+
+	if _, err := run(t, dir, find, "", "gen", "-delete"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := run(t, dir, find, "", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := ".\n./keep.txt\n"; got != want {
+		t.Errorf("after find -delete = %q, want %q", got, want)
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		{".", "-name"},
+		{".", "-type", "x"},
+		{".", "-maxdepth", "-1"},
+		{".", "(", "-name", "a"},
+		{".", "-exec", "echo"},
+		{".", "-unknown"},
+		{".", "-name", "["},
+	} {
+		if _, err := run(t, t.TempDir(), find, "", args...); err == nil {
+			t.Errorf("find %q succeeded", args)
+		}
+	}
+}
+
+func TestFnmatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		fold    bool
+		subject string
+		want    bool
+	}{
+		{"*.go", false, "main.go", true},
+		{"*.go", false, "main.GO", false},
+		{"*.go", true, "main.GO", true},
+		{"*.go", false, "dir/main.go", true},
+		{"?.md", false, "a.md", true},
+		{"?.md", false, "ab.md", false},
+		{"[ab].txt", false, "b.txt", true},
+		{"[!ab].txt", false, "b.txt", false},
+		{"[!ab].txt", false, "c.txt", true},
+		{`\*`, false, "*", true},
+		{`\*`, false, "a", false},
+		{"a.b", false, "axb", false},
+	}
+
+	for _, tt := range tests {
+		re, err := fnmatch(tt.pattern, tt.fold)
+		if err != nil {
+			t.Errorf("fnmatch(%q): %v", tt.pattern, err)
+
+			continue
+		}
+
+		if got := re.MatchString(tt.subject); got != tt.want {
+			t.Errorf("fnmatch(%q, %v) on %q = %v, want %v", tt.pattern, tt.fold, tt.subject, got, tt.want)
+		}
+	}
+}
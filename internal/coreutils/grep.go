@@ -0,0 +1,262 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// grepError is the exit status of grep on errors (no match is 1).
+const grepError = 2
+
+type grepper struct {
+	env      *Env
+	re       *regexp.Regexp
+	invert   bool
+	number   bool
+	count    bool
+	list     bool
+	quiet    bool
+	silent   bool
+	only     bool
+	withName bool
+	matched  bool
+	failed   bool
+}
+
+func grep(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("grep")
+	exprs := flags.StringArrayP("regexp", "e", nil, "use the pattern for matching")
+	fold := flags.BoolP("ignore-case", "i", false, "ignore case distinctions")
+	extended := flags.BoolP("extended-regexp", "E", false, "patterns are extended regular expressions")
+	fixed := flags.BoolP("fixed-strings", "F", false, "patterns are strings")
+	word := flags.BoolP("word-regexp", "w", false, "match only whole words")
+	line := flags.BoolP("line-regexp", "x", false, "match only whole lines")
+	recursive := flags.BoolP("recursive", "r", false, "read all files under each directory")
+	flags.BoolP("dereference-recursive", "R", false, "read all files under each directory")
+	withName := flags.BoolP("with-filename", "H", false, "print the file name for each match")
+	noName := flags.BoolP("no-filename", "h", false, "suppress the file name prefix")
+
+	grp := &grepper{env: env}
+
+	flags.BoolVarP(&grp.invert, "invert-match", "v", false, "select non-matching lines")
+	flags.BoolVarP(&grp.number, "line-number", "n", false, "print line number with output lines")
+	flags.BoolVarP(&grp.count, "count", "c", false, "print only a count of selected lines per file")
+	flags.BoolVarP(&grp.list, "files-with-matches", "l", false, "print only names of files with selected lines")
+	flags.BoolVarP(&grp.quiet, "quiet", "q", false, "suppress all normal output")
+	flags.BoolVarP(&grp.silent, "no-messages", "s", false, "suppress error messages")
+	flags.BoolVarP(&grp.only, "only-matching", "o", false, "show only the matching parts of lines")
+
+	if err := flags.Parse(args); err != nil {
+		fmt.Fprintf(env.Stderr, "grep: %v\n", err)
+
+		return Status(grepError)
+	}
+
+	files := flags.Args()
+	patterns := *exprs
+
+	if len(patterns) == 0 {
+		if len(files) == 0 {
+			fmt.Fprintf(env.Stderr, "grep: %v\n", errMissingOperand)
+
+			return Status(grepError)
+		}
+
+		patterns, files = files[:1], files[1:]
+	}
+
+	*recursive = *recursive || flags.Lookup("dereference-recursive").Changed
+
+	re, err := grepRegexp(patterns, *extended, *fixed, *fold, *word, *line)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "grep: %v\n", err)
+
+		return Status(grepError)
+	}
+
+	grp.re = re
+
+	if *recursive && len(files) == 0 {
+		files = []string{"."}
+	}
+
+	grp.withName = (len(files) > 1 || *recursive || *withName) && !*noName
+
+	return grp.run(files, *recursive)
+}
+
+func grepRegexp(patterns []string, extended, fixed, fold, word, line bool) (*regexp.Regexp, error) {
+	var alternatives []string
+
+	for _, pattern := range patterns {
+		for _, expr := range strings.Split(pattern, "\n") {
+			if fixed {
+				expr = regexp.QuoteMeta(expr)
+			} else {
+				expr = convertRegexp(expr, extended)
+			}
+
+			alternatives = append(alternatives, "(?:"+expr+")")
+		}
+	}
+
+	expr := strings.Join(alternatives, "|")
+
+	switch {
+	case line:
+		expr = "^(?:" + expr + ")$"
+	case word:
+		expr = `\b(?:` + expr + `)\b`
+	}
+
+	if fold {
+		expr = "(?i)" + expr
+	}
+
+	return regexp.Compile(expr)
+}
+
+func (g *grepper) run(files []string, recursive bool) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	for _, name := range files {
+		if recursive && name != "-" {
+			g.walk(name)
+		} else {
+			g.file(name)
+		}
+
+		if g.quiet && g.matched {
+			return nil
+		}
+	}
+
+	switch {
+	case g.failed:
+		return Status(grepError)
+	case g.matched:
+		return nil
+	default:
+		return Status(1)
+	}
+}
+
+func (g *grepper) walk(root string) {
+	err := filepath.WalkDir(g.env.path(root), func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(g.env.path(root), name)
+		if err != nil {
+			return err
+		}
+
+		if rel != "." {
+			name = strings.TrimSuffix(root, "/") + "/" + filepath.ToSlash(rel)
+		} else {
+			name = root
+		}
+
+		g.file(name)
+
+		if g.quiet && g.matched {
+			return filepath.SkipAll
+		}
+
+		return nil
+	})
+	if err != nil {
+		g.error(err)
+	}
+}
+
+func (g *grepper) file(name string) {
+	err := g.env.withInput(name, func(name string, input io.Reader) error {
+		if name == "-" {
+			name = "(standard input)"
+		}
+
+		return g.search(name, input)
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		g.error(err)
+	}
+}
+
+func (g *grepper) error(err error) {
+	g.failed = true
+
+	if !g.silent {
+		fmt.Fprintf(g.env.Stderr, "grep: %v\n", err)
+	}
+}
+
+func (g *grepper) search(name string, input io.Reader) error {
+	prefix := ""
+	if g.withName {
+		prefix = name + ":"
+	}
+
+	count, lineno := 0, 0
+
+	err := eachLine(input, func(line string) error {
+		lineno++
+
+		if g.re.MatchString(line) == g.invert {
+			return nil
+		}
+
+		count++
+		g.matched = true
+
+		switch {
+		case g.quiet || g.list:
+			return errStop
+		case g.count:
+			return nil
+		}
+
+		number := ""
+		if g.number {
+			number = fmt.Sprintf("%d:", lineno)
+		}
+
+		if g.only && !g.invert {
+			for _, match := range g.re.FindAllString(line, -1) {
+				fmt.Fprintf(g.env.Stdout, "%s%s%s\n", prefix, number, match)
+			}
+
+			return nil
+		}
+
+		_, err := fmt.Fprintf(g.env.Stdout, "%s%s%s\n", prefix, number, line)
+
+		return err
+	})
+
+	switch {
+	case g.quiet:
+	case g.list && count != 0:
+		fmt.Fprintln(g.env.Stdout, name)
+	case g.count:
+		fmt.Fprintf(g.env.Stdout, "%s%d\n", prefix, count)
+	}
+
+	return err
+}
+
+// errStop stops reading the input.
+var errStop = errors.New("stop")
@@ -0,0 +1,101 @@
+package coreutils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compileRegexp compiles the POSIX basic (or extended) regular expression.
+// The Go regular expression syntax is close to the extended one, the basic one is converted.
+func compileRegexp(expr string, extended bool, fold bool) (*regexp.Regexp, error) {
+	expr = convertRegexp(expr, extended)
+
+	if fold {
+		expr = "(?i)" + expr
+	}
+
+	return regexp.Compile(expr)
+}
+
+// convertRegexp converts the POSIX regular expression to Go syntax.
+// In basic regular expressions \( \) \{ \} \| \+ \? are the operators, the characters without backslash are literals.
+// A leading * is a literal, \< and \> are word boundaries. Backslash is a literal in bracket expressions.
+func convertRegexp(expr string, extended bool) string {
+	var buff strings.Builder
+
+	for idx := 0; idx < len(expr); idx++ {
+		char := expr[idx]
+
+		switch {
+		case char == '[':
+			end := bracketEnd(expr, idx)
+			if end < 0 {
+				buff.WriteString(`\[`)
+
+				continue
+			}
+
+			buff.WriteString(strings.ReplaceAll(expr[idx:end+1], `\`, `\\`))
+			idx = end
+		case char == '\\' && idx+1 < len(expr):
+			idx++
+			next := expr[idx]
+
+			switch {
+			case next == '<' || next == '>':
+				buff.WriteString(`\b`)
+			case !extended && strings.IndexByte("(){}|+?", next) >= 0:
+				buff.WriteByte(next)
+			default:
+				buff.WriteByte('\\')
+				buff.WriteByte(next)
+			}
+		case !extended && strings.IndexByte("(){}|+?", char) >= 0:
+			buff.WriteByte('\\')
+			buff.WriteByte(char)
+		case char == '*' && atStart(buff.String()):
+			buff.WriteString(`\*`)
+		default:
+			buff.WriteByte(char)
+		}
+	}
+
+	return buff.String()
+}
+
+// bracketEnd returns the index of the closing bracket of the bracket expression starting at start (-1 if none).
+func bracketEnd(expr string, start int) int {
+	idx := start + 1
+
+	if idx < len(expr) && expr[idx] == '^' {
+		idx++
+	}
+
+	// a leading ] is a member of the bracket expression
+	if idx < len(expr) && expr[idx] == ']' {
+		idx++
+	}
+
+	for ; idx < len(expr); idx++ {
+		switch {
+		case expr[idx] == ']':
+			return idx
+		case expr[idx] == '[' && idx+1 < len(expr) && strings.IndexByte(":.=", expr[idx+1]) >= 0:
+			end := strings.Index(expr[idx+2:], string(expr[idx+1])+"]")
+			if end < 0 {
+				return -1
+			}
+
+			idx += 2 + end + 1
+		}
+	}
+
+	return -1
+}
+
+// atStart reports whether a * would have nothing to repeat at the end of the converted expression.
+func atStart(converted string) bool {
+	return len(converted) == 0 || strings.HasSuffix(converted, "(") ||
+		(strings.HasSuffix(converted, "^") && !strings.HasSuffix(converted, `\^`)) ||
+		(strings.HasSuffix(converted, "|") && !strings.HasSuffix(converted, `\|`))
+}
@@ -0,0 +1,585 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sed supports the s, y, d, p, q, =, a, i and c commands with line number, $ and regular expression addresses
+// (and ranges of them). Blocks and the hold space are not supported.
+func sed(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("sed")
+	quiet := flags.BoolP("quiet", "n", false, "suppress automatic printing of pattern space")
+	scripts := flags.StringArrayP("expression", "e", nil, "add the script to the commands to be executed")
+	extended := flags.BoolP("regexp-extended", "E", false, "use extended regular expressions in the script")
+	flags.BoolP("r", "r", false, "use extended regular expressions in the script")
+	inplace := flags.BoolP("in-place", "i", false, "edit files in place")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files := flags.Args()
+
+	if len(*scripts) == 0 {
+		if len(files) == 0 {
+			return errMissingOperand
+		}
+
+		*scripts, files = files[:1], files[1:]
+	}
+
+	parser := &sedParser{script: strings.Join(*scripts, "\n"), extended: *extended || flags.Lookup("r").Changed}
+
+	cmds, err := parser.parse()
+	if err != nil {
+		return err
+	}
+
+	if !*inplace {
+		lines, err := env.readLines(files)
+		if err != nil {
+			return err
+		}
+
+		return (&sedRun{cmds: cmds, quiet: *quiet, out: env.Stdout}).run(lines)
+	}
+
+	for _, name := range files {
+		data, err := os.ReadFile(env.path(name))
+		if err != nil {
+			return err
+		}
+
+		var out strings.Builder
+
+		var lines []string
+		if len(data) != 0 {
+			lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+
+		if err := (&sedRun{cmds: cmds, quiet: *quiet, out: &out}).run(lines); err != nil {
+			return err
+		}
+
+		const perm = 0o644
+
+		if err := os.WriteFile(env.path(name), []byte(out.String()), perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type sedAddr struct {
+	line int
+	last bool
+	re   *regexp.Regexp
+}
+
+func (a *sedAddr) match(lineno int, space string, last bool) bool {
+	switch {
+	case a.re != nil:
+		return a.re.MatchString(space)
+	case a.last:
+		return last
+	default:
+		return a.line == lineno
+	}
+}
+
+type sedCmd struct {
+	addr1, addr2 *sedAddr
+	negate       bool
+	name         byte
+	re           *regexp.Regexp
+	replacement  string
+	global       bool
+	nth          int
+	print        bool
+	from, to     []rune
+	text         string
+	// active is true inside of the address range
+	active bool
+}
+
+func (c *sedCmd) selects(lineno int, space string, last bool) bool {
+	return c.match(lineno, space, last) != c.negate
+}
+
+func (c *sedCmd) match(lineno int, space string, last bool) bool {
+	switch {
+	case c.addr1 == nil:
+		return true
+	case c.addr2 == nil:
+		return c.addr1.match(lineno, space, last)
+	case c.active:
+		// a line number end address less than the current line ends the range
+		if c.addr2.match(lineno, space, last) || (c.addr2.re == nil && !c.addr2.last && lineno >= c.addr2.line) {
+			c.active = false
+		}
+
+		return true
+	case c.addr1.match(lineno, space, last):
+		switch {
+		case c.addr2.re != nil:
+			c.active = true
+		case c.addr2.last:
+			c.active = !last
+		default:
+			c.active = lineno < c.addr2.line
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// substitute executes the s command, it reports whether a replacement was made.
+func (c *sedCmd) substitute(space string) (string, bool) {
+	matches := c.re.FindAllStringSubmatchIndex(space, -1)
+
+	nth := max(c.nth, 1)
+	if len(matches) < nth {
+		return space, false
+	}
+
+	matches = matches[nth-1:]
+	if !c.global {
+		matches = matches[:1]
+	}
+
+	var buff strings.Builder
+
+	prev := 0
+
+	for _, match := range matches {
+		buff.WriteString(space[prev:match[0]])
+		c.expand(&buff, space, match)
+		prev = match[1]
+	}
+
+	buff.WriteString(space[prev:])
+
+	return buff.String(), true
+}
+
+// expand writes the replacement: & is the match, \1-\9 are the groups, \n is a newline.
+func (c *sedCmd) expand(buff *strings.Builder, space string, match []int) {
+	repl := c.replacement
+
+	for idx := 0; idx < len(repl); idx++ {
+		char := repl[idx]
+
+		switch {
+		case char == '&':
+			buff.WriteString(space[match[0]:match[1]])
+		case char == '\\' && idx+1 < len(repl):
+			idx++
+
+			switch next := repl[idx]; {
+			case next >= '1' && next <= '9':
+				group := int(next - '0')
+				if 2*group+1 < len(match) && match[2*group] >= 0 {
+					buff.WriteString(space[match[2*group]:match[2*group+1]])
+				}
+			case next == 'n':
+				buff.WriteByte('\n')
+			case next == 't':
+				buff.WriteByte('\t')
+			default:
+				buff.WriteByte(next)
+			}
+		default:
+			buff.WriteByte(char)
+		}
+	}
+}
+
+func (c *sedCmd) translate(space string) string {
+	return strings.Map(func(char rune) rune {
+		for idx, from := range c.from {
+			if from == char {
+				return c.to[idx]
+			}
+		}
+
+		return char
+	}, space)
+}
+
+type sedRun struct {
+	cmds  []*sedCmd
+	quiet bool
+	out   io.Writer
+}
+
+func (r *sedRun) run(lines []string) error {
+	for idx, line := range lines {
+		quit, err := r.cycle(idx+1, line, idx == len(lines)-1)
+		if err != nil || quit {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cycle executes the commands for the line, it reports whether the q command was executed.
+//
+//nolint:cyclop
+func (r *sedRun) cycle(lineno int, space string, last bool) (bool, error) {
+	var appended []string
+
+	deleted, quit := false, false
+
+loop:
+	for _, cmd := range r.cmds {
+		if !cmd.selects(lineno, space, last) {
+			continue
+		}
+
+		switch cmd.name {
+		case 's':
+			var replaced bool
+
+			if space, replaced = cmd.substitute(space); replaced && cmd.print {
+				fmt.Fprintln(r.out, space)
+			}
+		case 'y':
+			space = cmd.translate(space)
+		case 'p':
+			fmt.Fprintln(r.out, space)
+		case '=':
+			fmt.Fprintln(r.out, lineno)
+		case 'a':
+			appended = append(appended, cmd.text)
+		case 'i':
+			fmt.Fprintln(r.out, cmd.text)
+		case 'c':
+			// a range is changed to the text at the end of the range
+			if cmd.addr2 == nil || !cmd.active {
+				fmt.Fprintln(r.out, cmd.text)
+			}
+
+			deleted = true
+
+			break loop
+		case 'd':
+			deleted = true
+
+			break loop
+		case 'q':
+			quit = true
+
+			break loop
+		}
+	}
+
+	if !deleted && !r.quiet {
+		if _, err := fmt.Fprintln(r.out, space); err != nil {
+			return false, err
+		}
+	}
+
+	for _, text := range appended {
+		fmt.Fprintln(r.out, text)
+	}
+
+	return quit, nil
+}
+
+type sedParser struct {
+	script   string
+	pos      int
+	extended bool
+	// last is the last regular expression, used by the empty regular expression
+	last *regexp.Regexp
+}
+
+func (p *sedParser) parse() ([]*sedCmd, error) {
+	var cmds []*sedCmd
+
+	for {
+		p.skip(" \t\n;")
+
+		if p.pos >= len(p.script) {
+			return cmds, nil
+		}
+
+		cmd, err := p.command()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, p.script)
+		}
+
+		cmds = append(cmds, cmd)
+	}
+}
+
+func (p *sedParser) command() (*sedCmd, error) {
+	cmd := new(sedCmd)
+
+	var err error
+
+	if cmd.addr1, err = p.address(); err != nil {
+		return nil, err
+	}
+
+	if cmd.addr1 != nil && p.peek() == ',' {
+		p.pos++
+
+		if cmd.addr2, err = p.address(); err != nil {
+			return nil, err
+		}
+
+		if cmd.addr2 == nil {
+			return nil, errSedAddress
+		}
+	}
+
+	p.skip(" \t")
+
+	if p.peek() == '!' {
+		cmd.negate = true
+		p.pos++
+
+		p.skip(" \t")
+	}
+
+	if p.pos >= len(p.script) {
+		return nil, errSedCommand
+	}
+
+	cmd.name = p.script[p.pos]
+	p.pos++
+
+	switch cmd.name {
+	case 's':
+		return cmd, p.substitute(cmd)
+	case 'y':
+		return cmd, p.transliterate(cmd)
+	case 'a', 'i', 'c':
+		cmd.text = p.text()
+	case 'd', 'p', 'q', '=':
+	default:
+		return nil, fmt.Errorf("%w: %c", errSedCommand, cmd.name)
+	}
+
+	return cmd, nil
+}
+
+func (p *sedParser) address() (*sedAddr, error) {
+	switch char := p.peek(); {
+	case char >= '0' && char <= '9':
+		start := p.pos
+
+		for p.pos < len(p.script) && p.script[p.pos] >= '0' && p.script[p.pos] <= '9' {
+			p.pos++
+		}
+
+		line, err := strconv.Atoi(p.script[start:p.pos])
+		if err != nil {
+			return nil, err
+		}
+
+		return &sedAddr{line: line}, nil
+	case char == '$':
+		p.pos++
+
+		return &sedAddr{last: true}, nil
+	case char == '/' || char == '\\':
+		if char == '\\' {
+			p.pos++
+		}
+
+		delim := p.peek()
+		p.pos++
+
+		re, err := p.regexp(delim)
+		if err != nil {
+			return nil, err
+		}
+
+		return &sedAddr{re: re}, nil
+	default:
+		return nil, nil //nolint:nilnil
+	}
+}
+
+func (p *sedParser) regexp(delim byte) (*regexp.Regexp, error) {
+	expr, err := p.part(delim)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expr) == 0 {
+		if p.last == nil {
+			return nil, errSedRegexp
+		}
+
+		return p.last, nil
+	}
+
+	re, err := compileRegexp(expr, p.extended, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p.last = re
+
+	return re, nil
+}
+
+// part returns the text until the unescaped delimiter, an escaped delimiter is the delimiter character itself.
+func (p *sedParser) part(delim byte) (string, error) {
+	var buff strings.Builder
+
+	for ; p.pos < len(p.script); p.pos++ {
+		char := p.script[p.pos]
+
+		if char == delim {
+			p.pos++
+
+			return buff.String(), nil
+		}
+
+		if char == '\\' && p.pos+1 < len(p.script) {
+			p.pos++
+
+			if p.script[p.pos] != delim {
+				buff.WriteByte('\\')
+			}
+
+			char = p.script[p.pos]
+		}
+
+		buff.WriteByte(char)
+	}
+
+	return "", errSedUnterminated
+}
+
+func (p *sedParser) substitute(cmd *sedCmd) error {
+	if p.pos >= len(p.script) {
+		return errSedUnterminated
+	}
+
+	delim := p.script[p.pos]
+	p.pos++
+
+	re, err := p.regexp(delim)
+	if err != nil {
+		return err
+	}
+
+	cmd.re = re
+
+	if cmd.replacement, err = p.part(delim); err != nil {
+		return err
+	}
+
+	for p.pos < len(p.script) && strings.IndexByte(";\n}", p.script[p.pos]) < 0 {
+		switch char := p.script[p.pos]; {
+		case char == 'g':
+			cmd.global = true
+		case char == 'p':
+			cmd.print = true
+		case char == 'i' || char == 'I':
+			cmd.re = regexp.MustCompile("(?i)" + cmd.re.String())
+		case char >= '0' && char <= '9':
+			cmd.nth = cmd.nth*10 + int(char-'0') //nolint:mnd
+		case char == ' ' || char == '\t':
+		default:
+			return fmt.Errorf("%w: %c", errSedFlag, char)
+		}
+
+		p.pos++
+	}
+
+	return nil
+}
+
+func (p *sedParser) transliterate(cmd *sedCmd) error {
+	if p.pos >= len(p.script) {
+		return errSedUnterminated
+	}
+
+	delim := p.script[p.pos]
+	p.pos++
+
+	from, err := p.part(delim)
+	if err != nil {
+		return err
+	}
+
+	to, err := p.part(delim)
+	if err != nil {
+		return err
+	}
+
+	cmd.from, cmd.to = unescape(from), unescape(to)
+
+	if len(cmd.from) != len(cmd.to) {
+		return errSedTransliterate
+	}
+
+	return nil
+}
+
+// text returns the text of the a, i and c commands (a\ followed by newline, a\text or a text).
+func (p *sedParser) text() string {
+	p.skip(" \t")
+
+	if strings.HasPrefix(p.script[p.pos:], "\\\n") {
+		p.pos += 2
+	} else if p.peek() == '\\' {
+		p.pos++
+	}
+
+	var buff strings.Builder
+
+	for ; p.pos < len(p.script) && p.script[p.pos] != '\n'; p.pos++ {
+		char := p.script[p.pos]
+
+		// a backslash at the end of the line continues the text
+		if char == '\\' && p.pos+1 < len(p.script) {
+			p.pos++
+			char = p.script[p.pos]
+		}
+
+		buff.WriteByte(char)
+	}
+
+	return buff.String()
+}
+
+func (p *sedParser) peek() byte {
+	if p.pos < len(p.script) {
+		return p.script[p.pos]
+	}
+
+	return 0
+}
+
+func (p *sedParser) skip(chars string) {
+	for p.pos < len(p.script) && strings.IndexByte(chars, p.script[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+var (
+	errSedAddress       = errors.New("unexpected ,")
+	errSedCommand       = errors.New("unknown command")
+	errSedRegexp        = errors.New("no previous regular expression")
+	errSedUnterminated  = errors.New("unterminated command")
+	errSedFlag          = errors.New("unknown option to s")
+	errSedTransliterate = errors.New("strings for y command are different lengths")
+)
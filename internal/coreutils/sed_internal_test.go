@@ -0,0 +1,98 @@
+package coreutils
+
+import (
+	"testing"
+)
+
+func TestSed(t *testing.T) {
+	t.Parallel()
+
+	const input = "one\ntwo\nthree\nfour\n"
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"substitute", []string{"s/o/0/"}, "0ne\ntw0\nthree\nf0ur\n"},
+		{"substitute global", []string{"s/[eo]/_/g"}, "_n_\ntw_\nthr__\nf_ur\n"},
+		{"substitute nth", []string{"s/e/E/2"}, "one\ntwo\nthreE\nfour\n"},
+		{"substitute delimiter", []string{"s|o|/|"}, "/ne\ntw/\nthree\nf/ur\n"},
+		{"substitute case insensitive", []string{"s/ONE/1/I"}, "1\ntwo\nthree\nfour\n"},
+		{"substitute group", []string{`s/\(t\)\(w\)/\2\1/`}, "one\nwto\nthree\nfour\n"},
+		{"substitute extended group", []string{"-E", "s/(t)(h)/<\\2\\1>/"}, "one\ntwo\n<ht>ree\nfour\n"},
+		{"substitute whole match", []string{"s/.*/[&]/"}, "[one]\n[two]\n[three]\n[four]\n"},
+		{"substitute escaped ampersand", []string{`s/o/\&/`}, "&ne\ntw&\nthree\nf&ur\n"},
+		{"substitute print", []string{"-n", "s/t/T/p"}, "Two\nThree\n"},
+		{"line address", []string{"2d"}, "one\nthree\nfour\n"},
+		{"last line", []string{"-n", "$p"}, "four\n"},
+		{"line range", []string{"-n", "2,3p"}, "two\nthree\n"},
+		{"regexp address", []string{"/^t/d"}, "one\nfour\n"},
+		{"custom regexp delimiter", []string{`\,^t,d`}, "one\nfour\n"},
+		{"regexp range", []string{"/two/,/three/d"}, "one\nfour\n"},
+		{"negated address", []string{"2!d"}, "two\n"},
+		{"quit", []string{"2q"}, "one\ntwo\n"},
+		{"line number", []string{"-n", "/four/="}, "4\n"},
+		{"transliterate", []string{"y/otw/OTW/"}, "One\nTWO\nThree\nfOur\n"},
+		{"append", []string{"1a added"}, "one\nadded\ntwo\nthree\nfour\n"},
+		{"insert", []string{"$i\\\ninserted"}, "one\ntwo\nthree\ninserted\nfour\n"},
+		{"change", []string{"2,3c changed"}, "one\nchanged\nfour\n"},
+		{"multiple commands", []string{"1d;s/t/T/;3q"}, "Two\nThree\n"},
+		{"expressions", []string{"-e", "1d", "-e", "$d"}, "two\nthree\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := run(t, t.TempDir(), sed, input, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("sed %q = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSedParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, script := range []string{
+		"s/a/b",
+		"s/a",
+		"s/a/b/x",
+		"y/ab/c/",
+		"/unterminated",
+		"k",
+		"1,",
+		"s/\\(/x/",
+	} {
+		parser := &sedParser{script: script}
+
+		if _, err := parser.parse(); err == nil {
+			t.Errorf("parse(%q) succeeded", script)
+		}
+	}
+}
+
+func TestSedInPlace(t *testing.T) {
+	t.Parallel()
+
+	dir := tree(t, "file.txt")
+
+	if _, err := run(t, dir, sed, "", "-i", "s/file/changed/", "file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := run(t, dir, cat, "", "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "changed.txt\n"; got != want {
+		t.Errorf("file contents = %q, want %q", got, want)
+	}
+}
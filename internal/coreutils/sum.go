@@ -0,0 +1,92 @@
+package coreutils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func sha256sum(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("sha256sum")
+	check := flags.BoolP("check", "c", false, "read checksums from the files and check them")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *check {
+		return checkSums(env, flags.Args())
+	}
+
+	return env.eachInput(flags.Args(), func(name string, input io.Reader) error {
+		sum, err := sha256Of(input)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(env.Stdout, "%s  %s\n", sum, name)
+
+		return err
+	})
+}
+
+// checkSums checks the checksums in the format printed by sha256sum.
+func checkSums(env *Env, files []string) error {
+	failed := 0
+
+	err := env.eachInput(files, func(_ string, input io.Reader) error {
+		return eachLine(input, func(line string) error {
+			want, name, found := strings.Cut(strings.TrimSpace(line), " ")
+			if !found {
+				return nil
+			}
+
+			// the binary mode marker
+			name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+
+			var got string
+
+			err := env.withInput(name, func(_ string, input io.Reader) error {
+				var err error
+
+				got, err = sha256Of(input)
+
+				return err
+			})
+
+			if err == nil && strings.EqualFold(got, want) {
+				fmt.Fprintf(env.Stdout, "%s: OK\n", name)
+			} else {
+				failed++
+
+				fmt.Fprintf(env.Stdout, "%s: FAILED\n", name)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed != 0 {
+		fmt.Fprintf(env.Stderr, "sha256sum: WARNING: %d computed checksum(s) did NOT match\n", failed)
+
+		return Status(1)
+	}
+
+	return nil
+}
+
+func sha256Of(input io.Reader) (string, error) {
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, input); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,410 @@
+package coreutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func head(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("head")
+	lines := flags.IntP("lines", "n", 10, "print the first N lines") //nolint:mnd
+
+	if err := flags.Parse(legacyCount(args)); err != nil {
+		return err
+	}
+
+	return env.eachInput(flags.Args(), func(name string, input io.Reader) error {
+		if flags.NArg() > 1 {
+			fmt.Fprintf(env.Stdout, "==> %s <==\n", name)
+		}
+
+		count := 0
+
+		return eachLine(input, func(line string) error {
+			if count >= *lines {
+				return nil
+			}
+
+			count++
+
+			_, err := fmt.Fprintln(env.Stdout, line)
+
+			return err
+		})
+	})
+}
+
+func tail(_ context.Context, env *Env, args []string) error {
+	args = legacyCount(args)
+
+	// tail -n +N prints the lines starting with the Nth
+	from := 0
+
+	for idx := 0; idx+1 < len(args); idx++ {
+		if args[idx] == "-n" && strings.HasPrefix(args[idx+1], "+") {
+			from, _ = strconv.Atoi(args[idx+1][1:])
+			args = append(args[:idx:idx], args[idx+2:]...)
+
+			break
+		}
+	}
+
+	flags := newFlags("tail")
+	lines := flags.IntP("lines", "n", 10, "print the last N lines") //nolint:mnd
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return env.eachInput(flags.Args(), func(name string, input io.Reader) error {
+		if flags.NArg() > 1 {
+			fmt.Fprintf(env.Stdout, "==> %s <==\n", name)
+		}
+
+		var all []string
+
+		if err := eachLine(input, func(line string) error {
+			all = append(all, line)
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if from > 0 {
+			all = all[min(from-1, len(all)):]
+		} else {
+			all = all[max(len(all)-*lines, 0):]
+		}
+
+		for _, line := range all {
+			if _, err := fmt.Fprintln(env.Stdout, line); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func wc(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("wc")
+	lines := flags.BoolP("lines", "l", false, "print the line counts")
+	words := flags.BoolP("words", "w", false, "print the word counts")
+	bytes := flags.BoolP("bytes", "c", false, "print the byte counts")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !*lines && !*words && !*bytes {
+		*lines, *words, *bytes = true, true, true
+	}
+
+	// the counts of a single file or a single count of the standard input are printed without padding
+	single := flags.NArg() == 1 || (flags.NArg() == 0 && countTrue(*lines, *words, *bytes) == 1)
+
+	var total [3]int
+
+	report := func(counts [3]int, name string) {
+		var fields []string
+
+		for idx, enabled := range []bool{*lines, *words, *bytes} {
+			if !enabled {
+				continue
+			}
+
+			if single {
+				fields = append(fields, strconv.Itoa(counts[idx]))
+			} else {
+				fields = append(fields, fmt.Sprintf("%7d", counts[idx]))
+			}
+		}
+
+		if len(name) != 0 {
+			fields = append(fields, name)
+		}
+
+		fmt.Fprintln(env.Stdout, strings.Join(fields, " "))
+	}
+
+	err := env.eachInput(flags.Args(), func(name string, input io.Reader) error {
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return err
+		}
+
+		counts := [3]int{strings.Count(string(data), "\n"), len(strings.Fields(string(data))), len(data)}
+
+		for idx := range total {
+			total[idx] += counts[idx]
+		}
+
+		if name == "-" {
+			name = ""
+		}
+
+		report(counts, name)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		report(total, "total")
+	}
+
+	return nil
+}
+
+func countTrue(values ...bool) int {
+	count := 0
+
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+
+	return count
+}
+
+func sortCmd(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("sort")
+	reverse := flags.BoolP("reverse", "r", false, "reverse the result of comparisons")
+	numeric := flags.BoolP("numeric-sort", "n", false, "compare according to string numerical value")
+	unique := flags.BoolP("unique", "u", false, "output only the first of equal lines")
+	fold := flags.BoolP("ignore-case", "f", false, "fold lower case to upper case characters")
+	keydef := flags.StringP("key", "k", "", "sort via a key (field number[,field number])")
+	separator := flags.StringP("field-separator", "t", "", "use the separator instead of blanks")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	lines, err := env.readLines(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	keyOf, keyNumeric, keyReverse, err := parseKey(*keydef, *separator)
+	if err != nil {
+		return err
+	}
+
+	*numeric = *numeric || keyNumeric
+	*reverse = *reverse || keyReverse
+
+	compare := func(a, b string) int {
+		a, b = keyOf(a), keyOf(b)
+
+		if *fold {
+			a, b = strings.ToUpper(a), strings.ToUpper(b)
+		}
+
+		if *numeric {
+			if na, nb := leadingNumber(a), leadingNumber(b); na != nb {
+				if na < nb {
+					return -1
+				}
+
+				return 1
+			}
+
+			return 0
+		}
+
+		return strings.Compare(a, b)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		if *reverse {
+			return compare(lines[j], lines[i]) < 0
+		}
+
+		return compare(lines[i], lines[j]) < 0
+	})
+
+	for idx, line := range lines {
+		if *unique && idx > 0 && compare(lines[idx-1], line) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintln(env.Stdout, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseKey parses the key definition of sort (-k 2 or -k 2,3 with optional n and r modifiers).
+func parseKey(keydef string, separator string) (func(string) string, bool, bool, error) {
+	if len(keydef) == 0 {
+		return func(line string) string { return line }, false, false, nil
+	}
+
+	numeric := strings.Contains(keydef, "n")
+	reverse := strings.Contains(keydef, "r")
+	keydef = strings.TrimRight(strings.NewReplacer("n", "", "r", "", "b", "").Replace(keydef), " ")
+
+	startdef, enddef, hasEnd := strings.Cut(keydef, ",")
+
+	start, err := strconv.Atoi(startdef)
+	if err != nil || start < 1 {
+		return nil, false, false, fmt.Errorf("%w: %s", errInvalidNumber, keydef)
+	}
+
+	end := -1
+
+	if hasEnd {
+		if end, err = strconv.Atoi(enddef); err != nil || end < start {
+			return nil, false, false, fmt.Errorf("%w: %s", errInvalidNumber, keydef)
+		}
+	}
+
+	sep := " "
+	if len(separator) != 0 {
+		sep = separator
+	}
+
+	return func(line string) string {
+		var fields []string
+
+		if len(separator) != 0 {
+			fields = strings.Split(line, separator)
+		} else {
+			fields = strings.Fields(line)
+		}
+
+		if start > len(fields) {
+			return ""
+		}
+
+		if end < 0 || end > len(fields) {
+			return strings.Join(fields[start-1:], sep)
+		}
+
+		return strings.Join(fields[start-1:end], sep)
+	}, numeric, reverse, nil
+}
+
+// leadingNumber returns the numeric prefix of the string (0 if there is none).
+func leadingNumber(str string) float64 {
+	str = strings.TrimSpace(str)
+
+	end := 0
+
+	for end < len(str) && (unicode.IsDigit(rune(str[end])) || str[end] == '.' || (end == 0 && str[end] == '-')) {
+		end++
+	}
+
+	value, _ := strconv.ParseFloat(str[:end], 64)
+
+	return value
+}
+
+func uniq(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("uniq")
+	count := flags.BoolP("count", "c", false, "prefix lines by the number of occurrences")
+	repeated := flags.BoolP("repeated", "d", false, "only print duplicate lines")
+	unique := flags.BoolP("unique", "u", false, "only print unique lines")
+	fold := flags.BoolP("ignore-case", "i", false, "ignore differences in case when comparing")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return fmt.Errorf("%w: %s", errExtraOperand, flags.Arg(1))
+	}
+
+	lines, err := env.readLines(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	equal := func(a, b string) bool {
+		if *fold {
+			return strings.EqualFold(a, b)
+		}
+
+		return a == b
+	}
+
+	for start := 0; start < len(lines); {
+		end := start + 1
+		for end < len(lines) && equal(lines[start], lines[end]) {
+			end++
+		}
+
+		occurrences := end - start
+
+		if (!*repeated || occurrences > 1) && (!*unique || occurrences == 1) {
+			if *count {
+				fmt.Fprintf(env.Stdout, "%7d %s\n", occurrences, lines[start])
+			} else {
+				fmt.Fprintln(env.Stdout, lines[start])
+			}
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+func dirname(_ context.Context, env *Env, args []string) error {
+	if len(args) == 0 {
+		return errMissingOperand
+	}
+
+	for _, arg := range args {
+		name := strings.TrimRight(filepath.ToSlash(arg), "/")
+		if len(name) == 0 {
+			fmt.Fprintln(env.Stdout, "/")
+
+			continue
+		}
+
+		fmt.Fprintln(env.Stdout, path.Dir(name))
+	}
+
+	return nil
+}
+
+func basename(_ context.Context, env *Env, args []string) error {
+	if len(args) == 0 {
+		return errMissingOperand
+	}
+
+	if len(args) > 2 { //nolint:mnd
+		return fmt.Errorf("%w: %s", errExtraOperand, args[2])
+	}
+
+	name := strings.TrimRight(filepath.ToSlash(args[0]), "/")
+	if len(name) == 0 {
+		_, err := fmt.Fprintln(env.Stdout, "/")
+
+		return err
+	}
+
+	name = path.Base(name)
+
+	if len(args) == 2 && name != args[1] { //nolint:mnd
+		name = strings.TrimSuffix(name, args[1])
+	}
+
+	_, err := fmt.Fprintln(env.Stdout, name)
+
+	return err
+}
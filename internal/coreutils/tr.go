@@ -0,0 +1,206 @@
+package coreutils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func tr(_ context.Context, env *Env, args []string) error {
+	flags := newFlags("tr")
+	complement := flags.BoolP("complement", "c", false, "use the complement of the first set")
+	del := flags.BoolP("delete", "d", false, "delete characters in the first set")
+	squeeze := flags.BoolP("squeeze-repeats", "s", false, "replace repeated characters with a single one")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 || (flags.NArg() < 2 && !*del && !*squeeze) { //nolint:mnd
+		return errMissingOperand
+	}
+
+	set1 := expandSet(flags.Arg(0))
+	in1 := membership(set1, *complement)
+
+	var set2 []rune
+	if flags.NArg() > 1 {
+		set2 = expandSet(flags.Arg(1))
+	}
+
+	translate := func(char rune) rune { return char }
+
+	if !*del && len(set2) != 0 {
+		mapping := make(map[rune]rune, len(set1))
+
+		for idx, char := range set1 {
+			mapping[char] = set2[min(idx, len(set2)-1)]
+		}
+
+		translate = func(char rune) rune {
+			if *complement {
+				if !in1(char) {
+					return char
+				}
+
+				return set2[len(set2)-1]
+			}
+
+			if to, found := mapping[char]; found {
+				return to
+			}
+
+			return char
+		}
+	}
+
+	// the squeezed set is the last given set
+	squeezed := in1
+	if len(set2) != 0 {
+		squeezed = membership(set2, false)
+	}
+
+	reader := bufio.NewReader(env.Stdin)
+	writer := bufio.NewWriter(env.Stdout)
+
+	var last rune = -1
+
+	for {
+		char, _, err := reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if *del && in1(char) {
+			continue
+		}
+
+		char = translate(char)
+
+		if *squeeze && char == last && squeezed(char) {
+			continue
+		}
+
+		last = char
+
+		if _, err := writer.WriteRune(char); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func membership(set []rune, complement bool) func(rune) bool {
+	members := make(map[rune]struct{}, len(set))
+
+	for _, char := range set {
+		members[char] = struct{}{}
+	}
+
+	return func(char rune) bool {
+		_, found := members[char]
+
+		return found != complement
+	}
+}
+
+//nolint:gochecknoglobals
+var classes = map[string]func(rune) bool{
+	"alnum":  func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) },
+	"alpha":  unicode.IsLetter,
+	"blank":  func(r rune) bool { return r == ' ' || r == '\t' },
+	"digit":  unicode.IsDigit,
+	"lower":  unicode.IsLower,
+	"punct":  unicode.IsPunct,
+	"space":  unicode.IsSpace,
+	"upper":  unicode.IsUpper,
+	"xdigit": func(r rune) bool { return strings.ContainsRune("0123456789abcdefABCDEF", r) },
+}
+
+// expandSet expands the escapes, the ranges (a-z) and the character classes ([:digit:]) of the set.
+// The classes contain the ASCII characters only, so [:lower:] and [:upper:] are in the same order.
+func expandSet(spec string) []rune {
+	chars := unescape(spec)
+
+	var set []rune
+
+	for idx := 0; idx < len(chars); idx++ {
+		if chars[idx] == '[' && idx+1 < len(chars) && chars[idx+1] == ':' {
+			rest := string(chars[idx+2:])
+			if end := strings.Index(rest, ":]"); end > 0 {
+				if class, found := classes[rest[:end]]; found {
+					for char := rune(0); char < unicode.MaxASCII; char++ {
+						if class(char) {
+							set = append(set, char)
+						}
+					}
+
+					idx += 2 + len([]rune(rest[:end])) + 1
+
+					continue
+				}
+			}
+		}
+
+		if idx+2 < len(chars) && chars[idx+1] == '-' && chars[idx] <= chars[idx+2] {
+			for char := chars[idx]; char <= chars[idx+2]; char++ {
+				set = append(set, char)
+			}
+
+			idx += 2
+
+			continue
+		}
+
+		set = append(set, chars[idx])
+	}
+
+	return set
+}
+
+func unescape(spec string) []rune {
+	var chars []rune
+
+	runes := []rune(spec)
+
+	for idx := 0; idx < len(runes); idx++ {
+		if runes[idx] != '\\' || idx+1 == len(runes) {
+			chars = append(chars, runes[idx])
+
+			continue
+		}
+
+		idx++
+
+		switch runes[idx] {
+		case 'n':
+			chars = append(chars, '\n')
+		case 't':
+			chars = append(chars, '\t')
+		case 'r':
+			chars = append(chars, '\r')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := idx
+			for end < len(runes) && end < idx+3 && runes[end] >= '0' && runes[end] <= '7' {
+				end++
+			}
+
+			value, _ := strconv.ParseUint(string(runes[idx:end]), 8, 8)
+			chars = append(chars, rune(value))
+			idx = end - 1
+		default:
+			chars = append(chars, runes[idx])
+		}
+	}
+
+	return chars
+}
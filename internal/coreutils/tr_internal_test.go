@@ -0,0 +1,68 @@
+package coreutils
+
+import (
+	"testing"
+)
+
+func TestExpandSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"abc", "abc"},
+		{"a-e", "abcde"},
+		{"a-c0-2", "abc012"},
+		{"x-x", "x"},
+		{"z-a", "z-a"},
+		{"-a", "-a"},
+		{"a-", "a-"},
+		{"[:digit:]", "0123456789"},
+		{"[:xdigit:]", "0123456789ABCDEFabcdef"},
+		{"[:blank:]x", "\t x"},
+		{"[:unknown:]", "[:unknown:]"},
+		{`\n\t\\`, "\n\t\\"},
+		{`\101\60`, "A0"},
+	}
+
+	for _, tt := range tests {
+		if got := string(expandSet(tt.spec)); got != tt.want {
+			t.Errorf("expandSet(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestTr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args  []string
+		input string
+		want  string
+	}{
+		{[]string{"a-z", "A-Z"}, "hello, world", "HELLO, WORLD"},
+		{[]string{"[:lower:]", "[:upper:]"}, "abc", "ABC"},
+		{[]string{"abc", "x"}, "aabbcc", "xxxxxx"},
+		{[]string{"-d", "0-9"}, "a1b22c333", "abc"},
+		{[]string{"-s", " "}, "a   b  c", "a b c"},
+		{[]string{"-s", "a-z"}, "aabbccdd", "abcd"},
+		{[]string{"-cd", "a-z"}, "a1-b2_c", "abc"},
+		{[]string{"-c", "a-z", "_"}, "a1b2", "a_b_"},
+		{[]string{"-ds", "0-9", " "}, "a1  b2  c", "a b c"},
+		{[]string{" ", `\n`}, "a b", "a\nb"},
+	}
+
+	for _, tt := range tests {
+		got, err := run(t, t.TempDir(), tr, tt.input, tt.args...)
+		if err != nil {
+			t.Errorf("tr %q: %v", tt.args, err)
+
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("tr %q on %q = %q, want %q", tt.args, tt.input, got, tt.want)
+		}
+	}
+}
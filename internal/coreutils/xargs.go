@@ -0,0 +1,171 @@
+package coreutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xargsFailed is the exit status of xargs if a command invocation failed.
+const xargsFailed = 123
+
+func xargs(ctx context.Context, env *Env, args []string) error {
+	flags := newFlags("xargs")
+	maxArgs := flags.IntP("max-args", "n", 0, "use at most N arguments per command line")
+	replace := flags.StringP("replace", "I", "", "replace the string with the input lines")
+	null := flags.BoolP("null", "0", false, "items are separated by a null character")
+	noEmpty := flags.BoolP("no-run-if-empty", "r", false, "do not run the command if there is no input")
+
+	flags.SetInterspersed(false)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	command := flags.Args()
+	if len(command) == 0 {
+		command = []string{"echo"}
+	}
+
+	data, err := io.ReadAll(env.Stdin)
+	if err != nil {
+		return err
+	}
+
+	items, err := splitItems(string(data), *null, len(*replace) != 0)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 && (*noEmpty || len(*replace) != 0) {
+		return nil
+	}
+
+	var invocations [][]string
+
+	switch {
+	case len(*replace) != 0:
+		for _, item := range items {
+			invocation := make([]string, 0, len(command))
+
+			for _, arg := range command {
+				invocation = append(invocation, strings.ReplaceAll(arg, *replace, item))
+			}
+
+			invocations = append(invocations, invocation)
+		}
+	case *maxArgs > 0:
+		for start := 0; start < len(items) || start == 0; start += *maxArgs {
+			batch := items[start:min(start+*maxArgs, len(items))]
+			invocations = append(invocations, append(append([]string{}, command...), batch...))
+		}
+	default:
+		invocations = append(invocations, append(append([]string{}, command...), items...))
+	}
+
+	failed := false
+
+	for _, invocation := range invocations {
+		if err := xargsExec(ctx, env, invocation); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+
+			failed = true
+		}
+	}
+
+	if failed {
+		return Status(xargsFailed)
+	}
+
+	return nil
+}
+
+// xargsExec executes the command, echo (the default command) is a shell builtin, so it is handled here.
+func xargsExec(ctx context.Context, env *Env, args []string) error {
+	if args[0] == "echo" {
+		_, err := fmt.Fprintln(env.Stdout, strings.Join(args[1:], " "))
+
+		return err
+	}
+
+	return env.Exec(ctx, args)
+}
+
+// splitItems splits the input to items: by null characters, by lines (for replacement)
+// or by blanks (respecting quotes and backslashes).
+func splitItems(input string, null bool, lines bool) ([]string, error) {
+	switch {
+	case null:
+		return strings.FieldsFunc(input, func(r rune) bool { return r == 0 }), nil
+	case lines:
+		var items []string
+
+		for _, line := range strings.Split(input, "\n") {
+			if line = strings.TrimLeft(line, " \t"); len(line) != 0 {
+				items = append(items, line)
+			}
+		}
+
+		return items, nil
+	default:
+		return splitBlanks(input)
+	}
+}
+
+// splitBlanks splits the input at blanks and newlines, quotes and backslashes can be used to include them in an item.
+func splitBlanks(input string) ([]string, error) {
+	var (
+		items  []string
+		item   strings.Builder
+		quote  rune
+		inside bool
+	)
+
+	runes := []rune(input)
+
+	for idx := 0; idx < len(runes); idx++ {
+		char := runes[idx]
+
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				item.WriteRune(char)
+			}
+		case char == '\\' && idx+1 < len(runes):
+			idx++
+			item.WriteRune(runes[idx])
+			inside = true
+		case char == '\'' || char == '"':
+			quote = char
+			inside = true
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r':
+			if inside {
+				items = append(items, item.String())
+				item.Reset()
+
+				inside = false
+			}
+		default:
+			item.WriteRune(char)
+			inside = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errUnmatchedQuote
+	}
+
+	if inside {
+		items = append(items, item.String())
+	}
+
+	return items, nil
+}
+
+var errUnmatchedQuote = errors.New("unmatched quote")
@@ -0,0 +1,148 @@
+// Package fingerprint implements the up-to-date check of the tasks with sources and/or generated files.
+//
+// The fingerprint of a task invocation is the hash of the task definition (scripts and patterns),
+// the inputs of the invocation (arguments and parameter values) and the content of the source files.
+// The fingerprint of the last successful execution is stored in the state directory,
+// in a separate file for each invocation (task name and inputs).
+// The task is up to date if all generated patterns match existing files and the fingerprint is unchanged.
+package fingerprint
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/glob"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+// StateDir is the name of the state directory (in the task definitions directory).
+const StateDir = ".cdo"
+
+// Incremental reports whether the task has sources or generated files, so it can be up to date.
+func Incremental(t *task.Task) bool {
+	return len(t.Sources) != 0 || len(t.Generates) != 0
+}
+
+// UpToDate reports whether the task invocation with the given inputs and fingerprint (see Sum) is up to date.
+// The patterns are relative to dir, the state is stored in the statedir.
+func UpToDate(t *task.Task, dir string, statedir string, inputs []string, sum string) (bool, error) {
+	for _, pattern := range t.Generates {
+		files, err := glob.Files(dir, []string{pattern})
+		if err != nil {
+			return false, err
+		}
+
+		if len(files) == 0 {
+			return false, nil
+		}
+	}
+
+	stored, err := os.ReadFile(filename(statedir, t.Name, inputs))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return string(bytes.TrimSpace(stored)) == sum, nil
+}
+
+// Save stores the fingerprint of the successfully executed task invocation.
+// The fingerprint is computed before the execution, so the source files modified during the execution
+// make the task out of date.
+func Save(t *task.Task, statedir string, inputs []string, sum string) error {
+	name := filename(statedir, t.Name, inputs)
+
+	const dirperm, fileperm = 0o755, 0o644
+
+	if err := os.MkdirAll(filepath.Dir(name), dirperm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(name, []byte(sum+"\n"), fileperm)
+}
+
+// Sum returns the fingerprint of the task invocation.
+func Sum(t *task.Task, dir string, inputs []string) (string, error) {
+	hash := sha256.New()
+	write := writer(hash)
+
+	write(t.Name)
+	write(inputs...)
+	write(t.Sources...)
+	write(t.Generates...)
+
+	for _, step := range t.Steps {
+		write(step.Lang, step.Dir, step.If, strings.Join(step.OS, ","), string(step.Script))
+	}
+
+	files, err := glob.Files(dir, t.Sources)
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		sum, err := fileSum(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return "", err
+		}
+
+		write(file, sum)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func fileSum(name string) (string, error) {
+	file, err := os.Open(filepath.Clean(name))
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close() //nolint:errcheck
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writer returns a function writing the length prefixed values to the hash, so the values cannot run together.
+func writer(hash io.Writer) func(...string) {
+	return func(values ...string) {
+		for _, value := range values {
+			fmt.Fprintf(hash, "%d:%s\n", len(value), value)
+		}
+	}
+}
+
+// filename returns the name of the fingerprint file of the task invocation.
+// The namespace separator is not valid in file names on Windows.
+// The inputs are hashed, so every invocation (like the same task required with different arguments) has its own file.
+func filename(statedir string, name string, inputs []string) string {
+	base := strings.ReplaceAll(name, task.NamespaceSeparator, "__")
+
+	if len(inputs) != 0 {
+		hash := sha256.New()
+
+		writer(hash)(inputs...)
+
+		const suffixLen = 16
+
+		base += "-" + hex.EncodeToString(hash.Sum(nil))[:suffixLen]
+	}
+
+	return filepath.Join(statedir, "fingerprint", base)
+}
@@ -0,0 +1,71 @@
+package fingerprint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/fingerprint"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+func TestUpToDate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	statedir := filepath.Join(dir, fingerprint.StateDir)
+
+	if err := os.WriteFile(filepath.Join(dir, "in.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := &task.Task{Name: "ns:gen", Sources: []string{"in.txt"}}
+
+	sum := func(inputs ...string) string {
+		t.Helper()
+
+		sum, err := fingerprint.Sum(gen, dir, inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return sum
+	}
+
+	upToDate := func(inputs ...string) bool {
+		t.Helper()
+
+		ok, err := fingerprint.UpToDate(gen, dir, statedir, inputs, sum(inputs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ok
+	}
+
+	if upToDate("a") {
+		t.Fatal("up to date before the first execution")
+	}
+
+	for _, inputs := range [][]string{{"a"}, {"b"}, nil} {
+		if err := fingerprint.Save(gen, statedir, inputs, sum(inputs...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !upToDate("a") || !upToDate("b") || !upToDate() {
+		t.Error("the invocations with different inputs overwrite each other")
+	}
+
+	if upToDate("c") {
+		t.Error("invocation with new inputs is up to date")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "in.txt"), []byte("y"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if upToDate("a") {
+		t.Error("up to date after changing the source")
+	}
+}
@@ -0,0 +1,181 @@
+// Package glob implements glob patterns of slash separated paths. In addition to the usual wildcards,
+// the ** pattern matches any number of directories.
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Regexp returns the regular expression (without anchors) matching the same paths as the pattern.
+func Regexp(pattern string) string {
+	var buff strings.Builder
+
+	for idx := 0; idx < len(pattern); idx++ {
+		switch char := pattern[idx]; char {
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[idx:], "**/"):
+				buff.WriteString("(?:.*/)?")
+				idx += 2
+			case strings.HasPrefix(pattern[idx:], "**"):
+				buff.WriteString(".*")
+				idx++
+			default:
+				buff.WriteString("[^/]*")
+			}
+		case '?':
+			buff.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[idx:], ']')
+			if end < 0 {
+				buff.WriteString(`\[`)
+
+				continue
+			}
+
+			class := pattern[idx+1 : idx+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			buff.WriteString("[" + class + "]")
+			idx += end
+		case '\\':
+			if idx+1 < len(pattern) {
+				idx++
+				buff.WriteString(regexp.QuoteMeta(pattern[idx : idx+1]))
+			}
+		default:
+			buff.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	return buff.String()
+}
+
+// Compile returns the regular expression matching the whole path.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile("^" + Regexp(path.Clean(pattern)) + "$")
+	if err != nil {
+		return nil, errors.Join(ErrBadPattern, err)
+	}
+
+	return re, nil
+}
+
+// Files returns the files in the directory matching any of the patterns,
+// as sorted slash separated paths relative to the directory.
+func Files(dir string, patterns []string) ([]string, error) {
+	found := make(map[string]struct{})
+
+	for _, pattern := range patterns {
+		if err := files(dir, pattern, found); err != nil {
+			return nil, err
+		}
+	}
+
+	all := make([]string, 0, len(found))
+
+	for name := range found {
+		all = append(all, name)
+	}
+
+	sort.Strings(all)
+
+	return all, nil
+}
+
+func files(dir string, pattern string, found map[string]struct{}) error {
+	pattern = path.Clean(pattern)
+
+	re, err := Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(pattern, "**") {
+		return shallow(dir, pattern, found)
+	}
+
+	root := filepath.Join(dir, filepath.FromSlash(prefix(pattern)))
+
+	err = filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+
+		if rel = filepath.ToSlash(rel); re.MatchString(rel) {
+			found[rel] = struct{}{}
+		}
+
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// shallow finds the files matching the pattern without ** (so without walking the whole directory tree).
+func shallow(dir string, pattern string, found map[string]struct{}) error {
+	matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+	if err != nil {
+		return errors.Join(ErrBadPattern, err)
+	}
+
+	for _, name := range matches {
+		if info, err := os.Stat(name); err != nil || info.IsDir() {
+			continue
+		}
+
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+
+		found[filepath.ToSlash(rel)] = struct{}{}
+	}
+
+	return nil
+}
+
+// prefix returns the leading directories of the pattern without wildcards.
+func prefix(pattern string) string {
+	dirs := strings.Split(pattern, "/")
+
+	for idx, dir := range dirs[:len(dirs)-1] {
+		if strings.ContainsAny(dir, `*?[\`) {
+			return strings.Join(dirs[:idx], "/")
+		}
+	}
+
+	return path.Dir(pattern)
+}
+
+// ErrBadPattern is returned for invalid patterns.
+var ErrBadPattern = path.ErrBadPattern
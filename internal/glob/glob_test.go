@@ -0,0 +1,108 @@
+package glob_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/glob"
+)
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/main.go", true},
+		{"**/*.go", "internal/cmd/main.go", true},
+		{"cmd/**", "cmd/a/b.go", true},
+		{"cmd/**", "other/a.go", false},
+		{"cmd/**/*.md", "cmd/x/y/z.md", true},
+		{"cmd/**/*.md", "cmd/z.md", true},
+		{"?.txt", "a.txt", true},
+		{"?.txt", "a/b.txt", false},
+		{"[ab].txt", "b.txt", true},
+		{"[!ab].txt", "b.txt", false},
+		{"[!ab].txt", "c.txt", true},
+		{`\*.txt`, "*.txt", true},
+		{`\*.txt`, "a.txt", false},
+		{"./docs/*.md", "docs/a.md", true},
+		{"a.b", "axb", false},
+	}
+
+	for _, tt := range tests {
+		re, err := glob.Compile(tt.pattern)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.pattern, err)
+
+			continue
+		}
+
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("Compile(%q) on %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	t.Parallel()
+
+	for _, pattern := range []string{"[", "a[b", `a\`} {
+		if _, err := glob.Compile(pattern); !errors.Is(err, glob.ErrBadPattern) {
+			t.Errorf("Compile(%q) error = %v, want %v", pattern, err, glob.ErrBadPattern)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"main.go", "README.md", "cmd/run.go", "cmd/sub/deep.go", "docs/a.md", ".git/x.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"*.go"}, []string{"main.go"}},
+		{[]string{"**/*.go"}, []string{"cmd/run.go", "cmd/sub/deep.go", "main.go"}},
+		{[]string{"cmd/**/*.go"}, []string{"cmd/run.go", "cmd/sub/deep.go"}},
+		{[]string{"cmd/*"}, []string{"cmd/run.go"}},
+		{[]string{"*.md", "docs/*.md"}, []string{"README.md", "docs/a.md"}},
+		{[]string{"*.go", "main.go"}, []string{"main.go"}},
+		{[]string{"missing/**/*.go"}, []string{}},
+		{[]string{"*.txt"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := glob.Files(dir, tt.patterns)
+		if err != nil {
+			t.Errorf("Files(%q): %v", tt.patterns, err)
+
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Files(%q) = %q, want %q", tt.patterns, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/task"
+)
+
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Generate returns the dependency graph of the tasks in the given format.
+func Generate(format string, tasks []*task.Task) ([]byte, error) {
+	switch format {
+	case FormatDOT:
+		return DOT(tasks), nil
+	case FormatMermaid:
+		return Mermaid(tasks), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+}
+
+// Closure returns the named task and all the tasks it requires (directly or indirectly), sorted by name.
+func Closure(tasks map[string]*task.Task, name string) ([]*task.Task, error) {
+	seen := make(map[string]*task.Task)
+
+	var visit func(name string) error
+
+	visit = func(name string) error {
+		if _, done := seen[name]; done {
+			return nil
+		}
+
+		found, has := tasks[name]
+		if !has {
+			return fmt.Errorf("%w: %s", errMissingTask, name)
+		}
+
+		seen[name] = found
+
+		for _, req := range found.Requires {
+			if err := visit(req[0]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := visit(name); err != nil {
+		return nil, err
+	}
+
+	all := make([]*task.Task, 0, len(seen))
+	for _, task := range seen {
+		all = append(all, task)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	return all, nil
+}
+
+// DOT returns the dependency graph in Graphviz DOT format.
+func DOT(tasks []*task.Task) []byte {
+	var buff bytes.Buffer
+
+	fmt.Fprintln(&buff, "digraph tasks {")
+	fmt.Fprintln(&buff, "  rankdir=LR;")
+	fmt.Fprintln(&buff, "  node [shape=box];")
+
+	for _, task := range tasks {
+		fmt.Fprintf(&buff, "  %q [tooltip=%q];\n", task.Name, task.Short)
+	}
+
+	for _, task := range tasks {
+		for _, req := range task.Requires {
+			if len(req) > 1 {
+				fmt.Fprintf(&buff, "  %q -> %q [label=%q];\n", task.Name, req[0], strings.Join(req[1:], " "))
+			} else {
+				fmt.Fprintf(&buff, "  %q -> %q;\n", task.Name, req[0])
+			}
+		}
+	}
+
+	fmt.Fprintln(&buff, "}")
+
+	return buff.Bytes()
+}
+
+// Mermaid returns the dependency graph as a Mermaid flowchart.
+func Mermaid(tasks []*task.Task) []byte {
+	var buff bytes.Buffer
+
+	fmt.Fprintln(&buff, "flowchart LR")
+
+	for _, task := range tasks {
+		fmt.Fprintf(&buff, "  %s[\"%s\"]\n", mermaidID(task.Name), mermaidEscape(task.Name))
+	}
+
+	for _, task := range tasks {
+		for _, req := range task.Requires {
+			if len(req) > 1 {
+				fmt.Fprintf(&buff, "  %s -->|\"%s\"| %s\n",
+					mermaidID(task.Name), mermaidEscape(strings.Join(req[1:], " ")), mermaidID(req[0]))
+			} else {
+				fmt.Fprintf(&buff, "  %s --> %s\n", mermaidID(task.Name), mermaidID(req[0]))
+			}
+		}
+	}
+
+	return buff.Bytes()
+}
+
+var reNonID = regexp.MustCompile(`\W`)
+
+func mermaidID(name string) string {
+	// the namespace separator is replaced with two underscores, so docs:build and docs-build are different
+	return "task_" + reNonID.ReplaceAllString(strings.ReplaceAll(name, task.NamespaceSeparator, "__"), "_")
+}
+
+func mermaidEscape(str string) string {
+	return strings.ReplaceAll(str, `"`, "#quot;")
+}
+
+var (
+	errUnknownFormat = errors.New("unknown graph format")
+	errMissingTask   = errors.New("missing task")
+)
@@ -2,13 +2,18 @@ package makefile
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/syntax"
 )
 
-func Generate(appname, srcname string, tasks []*task.Task) []byte {
+// Generate generates the Makefile from the tasks. The Makefile is expected to be executed in the base directory,
+// the commands of the included tasks change to the directory of the included file.
+func Generate(appname, srcname, basedir string, tasks []*task.Task) ([]byte, error) {
 	var buff bytes.Buffer
 
 	generateHeader(appname, srcname, &buff)
@@ -16,10 +21,12 @@ func Generate(appname, srcname string, tasks []*task.Task) []byte {
 	generateHelp(tasks, &buff)
 
 	for _, task := range tasks {
-		generateTask(task, &buff)
+		if err := generateTask(task, basedir, &buff); err != nil {
+			return nil, err
+		}
 	}
 
-	return buff.Bytes()
+	return buff.Bytes(), nil
 }
 
 func generateHeader(appname, srcname string, out *bytes.Buffer) {
@@ -29,25 +36,29 @@ func generateHeader(appname, srcname string, out *bytes.Buffer) {
 	fmt.Fprintln(out)
 }
 
-func generateTask(task *task.Task, out *bytes.Buffer) {
+func generateTask(task *task.Task, basedir string, out *bytes.Buffer) error {
 	if len(task.Short) > 0 {
 		fmt.Fprintf(out, "# %s\n", task.Short)
 	}
 
-	fmt.Fprintf(out, ".PHONY: %s\n%s: ", task.Name, task.Name)
+	fmt.Fprintf(out, ".PHONY: %s\n%s: ", target(task.Name), target(task.Name))
 
 	for idx, req := range task.Requires {
 		if idx > 0 {
 			out.WriteRune(' ')
 		}
 
-		out.WriteString(req[0])
+		out.WriteString(target(req[0]))
 	}
 
 	out.WriteRune('\n')
 
-	script := strings.TrimSpace(string(task.Script))
-	script = strings.ReplaceAll(script, "$", "$$")
+	script, err := script(task, basedir)
+	if err != nil {
+		return err
+	}
+
+	script = strings.TrimSpace(script)
 
 	if len(script) > 0 {
 		lines := strings.Split(script, "\n")
@@ -65,6 +76,8 @@ func generateTask(task *task.Task, out *bytes.Buffer) {
 	}
 
 	fmt.Fprintln(out)
+
+	return nil
 }
 
 func generateHelp(tasks []*task.Task, out *bytes.Buffer) {
@@ -88,6 +101,150 @@ func generateHelp(tasks []*task.Task, out *bytes.Buffer) {
 	fmt.Fprintln(out)
 }
 
+// script returns the script of the task with the $ characters escaped.
+// When the base directory of the steps changes (included tasks), the script changes the directory.
+// The scripts of the steps with interpreter are written to temporary files.
+// The steps with os, if or dir attributes are wrapped in the matching condition and directory change.
+func script(task *task.Task, basedir string) (string, error) {
+	var buff strings.Builder
+
+	base := ""
+
+	for _, step := range task.Steps {
+		if step.Base != base {
+			base = step.Base
+
+			fmt.Fprintf(&buff, "cd \"%s\"\n", baseDir(basedir, base))
+		}
+
+		closing := 0
+
+		if len(step.OS) != 0 {
+			fmt.Fprintf(&buff, "if uname -s | grep -qiE '^(%s)'; then :\n", strings.Join(unames(step.OS), "|"))
+			closing++
+		}
+
+		if len(step.If) != 0 {
+			fmt.Fprintf(&buff, "if %s; then :\n", escapeDollar(strings.TrimSpace(step.If)))
+			closing++
+		}
+
+		if len(step.Dir) != 0 {
+			dir := escapeDollar(filepath.ToSlash(step.Dir))
+			if !filepath.IsAbs(step.Dir) {
+				dir = baseDir(basedir, step.Base) + "/" + dir
+			}
+
+			fmt.Fprintf(&buff, "cd \"%s\"\n", dir)
+
+			// the next step changes back to its base directory
+			base = "\x00"
+		}
+
+		if step.Embedded() {
+			buff.WriteString(escapeDollar(string(step.Script)))
+
+			if !strings.HasSuffix(string(step.Script), "\n") {
+				buff.WriteString("\n")
+			}
+		} else {
+			call, err := interpreterCall(step)
+			if err != nil {
+				return "", fmt.Errorf("%w: %s", err, task.Name)
+			}
+
+			buff.WriteString(escapeDollar(call))
+		}
+
+		buff.WriteString(strings.Repeat("fi\n", closing))
+	}
+
+	return buff.String(), nil
+}
+
+// interpreterCall returns the commands writing the script of a step with interpreter to a temporary file
+// and invoking the interpreter with the file name. Every command is a single line.
+func interpreterCall(step *task.Step) (string, error) {
+	filename := `"$tmp/step` + step.Extension() + `"`
+
+	words := make([]string, 0, len(step.Interpreter)+1)
+
+	for _, word := range step.Interpreter {
+		if quoted, err := syntax.Quote(word, syntax.LangBash); err == nil {
+			word = quoted
+		}
+
+		words = append(words, word)
+	}
+
+	script, err := syntax.Quote(string(step.Script), syntax.LangBash)
+	if err != nil {
+		return "", errUnsupportedScript
+	}
+
+	return strings.Join([]string{
+		`tmp="$(mktemp -d)"`,
+		`printf '%s' ` + script + ` > ` + filename,
+		strings.Join(append(words, filename), " "),
+		`rm -rf "$tmp"`,
+	}, "\n") + "\n", nil
+}
+
+// baseDir returns the directory of the included file relative to the Makefile.
+func baseDir(basedir, base string) string {
+	dir := "$(CURDIR)"
+	if len(base) != 0 {
+		dir += "/" + relative(basedir, base)
+	}
+
+	return dir
+}
+
+// unames returns the patterns of the uname -s output of the operating systems (GOOS values).
+func unames(goos []string) []string {
+	patterns := make([]string, 0, len(goos))
+
+	for _, name := range goos {
+		if name == "windows" {
+			patterns = append(patterns, "mingw", "msys", "cygwin", "windows")
+		} else {
+			patterns = append(patterns, name)
+		}
+	}
+
+	return patterns
+}
+
+func escapeDollar(str string) string {
+	return strings.ReplaceAll(str, "$", "$$")
+}
+
+func relative(basedir, dir string) string {
+	absbase, err := filepath.Abs(basedir)
+	if err != nil {
+		return dir
+	}
+
+	absdir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+
+	reldir, err := filepath.Rel(absbase, absdir)
+	if err != nil {
+		return dir
+	}
+
+	return filepath.ToSlash(reldir)
+}
+
+// target returns the make target of the task, the namespace separator is escaped.
+func target(name string) string {
+	return strings.ReplaceAll(name, task.NamespaceSeparator, "\\"+task.NamespaceSeparator)
+}
+
 func escape(str string) string {
 	return strings.ReplaceAll(str, "'", "\\'")
 }
+
+var errUnsupportedScript = errors.New("script cannot be written to the Makefile (null character)")
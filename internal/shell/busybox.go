@@ -1,8 +1,18 @@
 package shell
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 
 	"mvdan.cc/sh/v3/expand"
 	"mvdan.cc/sh/v3/interp"
@@ -10,32 +20,151 @@ import (
 
 const busyboxCmd = "busybox"
 
-func busyBoxHandler(dir string, env expand.Environ) func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
-	cmd, err := interp.LookPathDir(dir, env, busyboxCmd)
+// The modes of busybox usage, any other value is the path of the busybox executable.
+const (
+	// BusyboxAuto uses busybox if it is in the search path.
+	BusyboxAuto = "auto"
+	// BusyboxAlways requires busybox in the search path.
+	BusyboxAlways = "always"
+	// BusyboxNever doesn't use busybox.
+	BusyboxNever = "never"
+)
+
+// busybox is the busybox executable with the list of its applets.
+type busybox struct {
+	path    string
+	applets []string
+}
+
+// findBusybox returns nil if busybox is not used.
+func findBusybox(ctx context.Context, dir string, env expand.Environ, mode string) (*busybox, error) {
+	name := mode
+
+	switch mode {
+	case BusyboxNever:
+		return nil, nil //nolint:nilnil
+	case "", BusyboxAuto, BusyboxAlways:
+		name = busyboxCmd
+	}
+
+	cmd, err := interp.LookPathDir(dir, env, name)
 	if err != nil {
-		return func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
-			return func(ctx context.Context, args []string) error {
-				return next(ctx, args)
-			}
+		switch mode {
+		case "", BusyboxAuto:
+			return nil, nil //nolint:nilnil
+		case BusyboxAlways:
+			return nil, errNoBusybox
+		default:
+			return nil, fmt.Errorf("%w: %s", errNoBusybox, name)
 		}
 	}
 
-	return func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
-		return func(ctx context.Context, args []string) error {
-			applet := args[0]
+	return &busybox{path: cmd, applets: listApplets(ctx, cmd)}, nil
+}
+
+func (b *busybox) supports(applet string) bool {
+	if b == nil {
+		return false
+	}
+
+	idx := sort.SearchStrings(b.applets, applet)
+
+	return idx < len(b.applets) && b.applets[idx] == applet
+}
+
+// command returns the arguments running the applet as busybox subcommand.
+func (b *busybox) command(args []string) []string {
+	argsMod := make([]string, len(args)+1)
+	argsMod[0] = b.path
+	copy(argsMod[1:], args)
+
+	return argsMod
+}
+
+//nolint:gochecknoglobals
+var appletCache = struct {
+	sync.Mutex
+	lists map[string][]string
+}{lists: make(map[string][]string)}
+
+// listApplets returns the applets supported by the busybox executable. The output of busybox --list is cached
+// in memory and in the user's cache directory, keyed by the path and the modification time of the executable.
+// If the query fails, the generated applet table is used.
+func listApplets(ctx context.Context, cmd string) []string {
+	info, err := os.Stat(cmd)
+	if err != nil {
+		return applets
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", cmd, info.ModTime().UnixNano())))
+	key := hex.EncodeToString(sum[:])
 
-			idx := sort.SearchStrings(applets, applet)
-			if idx >= len(applets) || applets[idx] != applet {
-				return next(ctx, args)
-			}
+	appletCache.Lock()
+	defer appletCache.Unlock()
 
-			argsMod := make([]string, len(args)+1)
-			argsMod[0] = cmd
-			copy(argsMod[1:], args)
+	if list, found := appletCache.lists[key]; found {
+		return list
+	}
+
+	cachefile := ""
+	if cachedir, err := os.UserCacheDir(); err == nil {
+		cachefile = filepath.Join(cachedir, appname, busyboxCmd, key)
+	}
 
-			return next(ctx, argsMod)
+	list := readApplets(cachefile)
+	if list == nil {
+		out, err := exec.CommandContext(ctx, cmd, "--list").Output() //nolint:gosec
+		if list = parseApplets(out); err != nil || list == nil {
+			return applets
 		}
+
+		saveApplets(cachefile, list)
+	}
+
+	appletCache.lists[key] = list
+
+	return list
+}
+
+func readApplets(filename string) []string {
+	if len(filename) == 0 {
+		return nil
 	}
+
+	data, err := os.ReadFile(filename) //nolint:gosec
+	if err != nil {
+		return nil
+	}
+
+	return parseApplets(data)
 }
 
+func saveApplets(filename string, list []string) {
+	if len(filename) == 0 {
+		return
+	}
+
+	// the cache is an optimization only, the errors are ignored
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err == nil {
+		_ = os.WriteFile(filename, []byte(strings.Join(list, "\n")+"\n"), 0o600)
+	}
+}
+
+// parseApplets returns the sorted list of applet names, one per line.
+func parseApplets(data []byte) []string {
+	var list []string
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if name := strings.TrimSpace(string(line)); len(name) != 0 {
+			list = append(list, name)
+		}
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+var errNoBusybox = errors.New("busybox not found")
+
 //go:generate busybox sh -c "(echo '// Code generated by busybox.go; DO NOT EDIT.\n\npackage shell\n\nvar applets = []string{'; busybox --list | busybox sed -e 's/^/\\t\"/g' -e 's/$/\",/g'; echo '}') > busybox_gen.go"
@@ -0,0 +1,64 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Check returns the syntax errors of the script and the condition of the step.
+// The scripts of the steps with interpreter are not checked.
+func Check(step *task.Step) []*task.Problem {
+	var problems []*task.Problem
+
+	if step.Embedded() {
+		if _, err := parse(step); err != nil {
+			problems = append(problems, problemOf(step, err))
+		}
+	}
+
+	if len(step.If) != 0 {
+		if _, err := syntax.NewParser().Parse(strings.NewReader(step.If), step.File); err != nil {
+			problems = append(problems, &task.Problem{
+				File: step.File,
+				Line: step.Line,
+				Err:  fmt.Errorf("%w: %s", errInvalidCondition, step.If),
+			})
+		}
+	}
+
+	return problems
+}
+
+// problemOf converts the (already located) syntax error to a problem.
+func problemOf(step *task.Step, err error) *task.Problem {
+	var (
+		perr syntax.ParseError
+		lerr syntax.LangError
+		pos  syntax.Pos
+		text string
+	)
+
+	switch {
+	case errors.As(err, &perr):
+		pos, text = perr.Pos, perr.Text
+	case errors.As(err, &lerr):
+		pos = lerr.Pos
+		lerr.Filename = ""
+		text = strings.TrimPrefix(lerr.Error(), lerr.Pos.String()+": ")
+	default:
+		return &task.Problem{File: step.File, Line: step.Line, Err: err}
+	}
+
+	return &task.Problem{
+		File:   step.File,
+		Line:   int(pos.Line()), //nolint:gosec
+		Column: int(pos.Col()),  //nolint:gosec
+		Err:    fmt.Errorf("%w: %s", errSyntax, text),
+	}
+}
+
+var errSyntax = errors.New("syntax error")
@@ -0,0 +1,66 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/szkiba/cdo/internal/environ"
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// CommandFunc runs the cdo command invoked by a script in-process. The arguments don't contain the command name,
+// the options contain the working directory, the exported variables and the standard streams of the script.
+// It returns false if the command is not handled in-process, then the cdo executable is run.
+type CommandFunc func(ctx context.Context, args []string, opts *Options) (bool, error)
+
+func commandHandler(fn CommandFunc) func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+	return func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+		return func(ctx context.Context, args []string) error {
+			if fn == nil || args[0] != appname {
+				return next(ctx, args)
+			}
+
+			hc := interp.HandlerCtx(ctx)
+
+			handled, err := fn(ctx, args[1:], &Options{
+				Dir:    hc.Dir,
+				Env:    exported(hc.Env),
+				Stdin:  hc.Stdin,
+				Stdout: hc.Stdout,
+				Stderr: hc.Stderr,
+			})
+			if !handled {
+				return next(ctx, args)
+			}
+
+			if err == nil || ctx.Err() != nil {
+				return err
+			}
+
+			// like the cdo executable, the error is printed and reported by the exit status
+			status, ok := interp.IsExitStatus(err)
+			if !ok {
+				status = 1
+			}
+
+			fmt.Fprintln(hc.Stderr, "Error:", err)
+
+			return interp.NewExitStatus(status)
+		}
+	}
+}
+
+func exported(env expand.Environ) environ.Environ {
+	vars := make(environ.Environ)
+
+	env.Each(func(name string, vr expand.Variable) bool {
+		if vr.Exported && vr.Kind == expand.String {
+			vars[name] = vr.Str
+		}
+
+		return true
+	})
+
+	return vars
+}
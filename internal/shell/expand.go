@@ -0,0 +1,226 @@
+package shell
+
+import (
+	"bytes"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Expand returns the script of the step with the parameter expansions replaced by their values.
+// Only the variables known before execution (environment variables and positional arguments) are expanded,
+// the other parameter expansions are kept as is. The variables assigned in the step or in the earlier steps
+// of the session (before) are not known, neither are the positional parameters after set or shift
+// and in the function bodies.
+func Expand(step *task.Step, before []*task.Step, args []string, env expand.Environ) ([]byte, error) {
+	file, err := parse(step)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &paramEnviron{args: args, env: env, assigned: make(map[string]struct{})}
+
+	for _, prev := range before {
+		if !prev.Embedded() {
+			continue
+		}
+
+		if prevFile, err := parse(prev); err == nil {
+			params.assign(prevFile)
+		}
+	}
+
+	params.assign(file)
+
+	expandNode(params, file)
+
+	var buff bytes.Buffer
+
+	if err := syntax.NewPrinter().Print(&buff, file); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+func expandNode(params *paramEnviron, root syntax.Node) {
+	cfg := &expand.Config{Env: params}
+
+	syntax.Walk(root, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.FuncDecl:
+			expandNode(params.function(), node.Body)
+
+			return false
+		case *syntax.CallExpr:
+			node.Args = expandWords(cfg, node.Args)
+		case *syntax.WordIter:
+			node.Items = expandWords(cfg, node.Items)
+		case *syntax.Word:
+			node.Parts = expandParts(cfg, node.Parts, false)
+		case *syntax.DblQuoted:
+			node.Parts = expandParts(cfg, node.Parts, true)
+		}
+
+		return true
+	})
+}
+
+// expandWords replaces the words consisting of a single $@ or $* (quoted or not) with the resulting fields,
+// each of them as a separate quoted word. An empty "$@" results no words.
+func expandWords(cfg *expand.Config, words []*syntax.Word) []*syntax.Word {
+	result := make([]*syntax.Word, 0, len(words))
+
+	for _, word := range words {
+		if !positionals(word) || !cfg.Env.Get("@").IsSet() {
+			result = append(result, word)
+
+			continue
+		}
+
+		fields, err := expand.Fields(cfg, word)
+		if err != nil {
+			result = append(result, word)
+
+			continue
+		}
+
+		for _, field := range fields {
+			value, err := syntax.Quote(field, syntax.LangBash)
+			if err != nil {
+				return words
+			}
+
+			result = append(result, &syntax.Word{Parts: []syntax.WordPart{
+				&syntax.Lit{ValuePos: word.Pos(), ValueEnd: word.End(), Value: value},
+			}})
+		}
+	}
+
+	return result
+}
+
+// positionals reports whether the word is a single $@ or $* parameter expansion (quoted or not).
+func positionals(word *syntax.Word) bool {
+	if len(word.Parts) != 1 {
+		return false
+	}
+
+	part := word.Parts[0]
+
+	if quoted, ok := part.(*syntax.DblQuoted); ok && len(quoted.Parts) == 1 {
+		part = quoted.Parts[0]
+	}
+
+	param, ok := part.(*syntax.ParamExp)
+
+	return ok && plain(param) && (param.Param.Value == "@" || param.Param.Value == "*")
+}
+
+func plain(param *syntax.ParamExp) bool {
+	return !param.Excl && !param.Length && !param.Width && param.Index == nil &&
+		param.Slice == nil && param.Repl == nil && param.Names == 0 && param.Exp == nil
+}
+
+func expandParts(cfg *expand.Config, parts []syntax.WordPart, quoted bool) []syntax.WordPart {
+	for idx, part := range parts {
+		param, ok := part.(*syntax.ParamExp)
+		if !ok || !cfg.Env.Get(param.Param.Value).IsSet() {
+			continue
+		}
+
+		// $@ and $* in other contexts may result several fields, they are kept as is (except "$*")
+		if cfg.Env.Get(param.Param.Value).Kind == expand.Indexed &&
+			(!quoted || param.Param.Value != "*" || !plain(param)) {
+			continue
+		}
+
+		value, err := expand.Literal(cfg, &syntax.Word{Parts: []syntax.WordPart{param}})
+		if err != nil {
+			continue
+		}
+
+		if quoted {
+			value = dblQuoteEscaper.Replace(value)
+		} else if value, err = syntax.Quote(value, syntax.LangBash); err != nil {
+			continue
+		}
+
+		parts[idx] = &syntax.Lit{ValuePos: param.Pos(), ValueEnd: param.End(), Value: value}
+	}
+
+	return parts
+}
+
+var dblQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`") //nolint:gochecknoglobals
+
+// paramEnviron makes the positional arguments available for the expander.
+// The assigned variables (and the positional parameters, if they are changed) are unset.
+type paramEnviron struct {
+	args     []string
+	env      expand.Environ
+	assigned map[string]struct{}
+}
+
+// assign collects the variables assigned in the script.
+func (p *paramEnviron) assign(file *syntax.File) {
+	syntax.Walk(file, func(node syntax.Node) bool {
+		assignments(node, func(name string) { p.assigned[name] = struct{}{} })
+
+		return true
+	})
+}
+
+// function returns the environment of the function bodies, where the positional parameters are the function arguments.
+func (p *paramEnviron) function() *paramEnviron {
+	assigned := maps.Clone(p.assigned)
+	assigned[positionalParams] = struct{}{}
+
+	return &paramEnviron{args: p.args, env: p.env, assigned: assigned}
+}
+
+//nolint:exhaustruct
+func (p *paramEnviron) Get(name string) expand.Variable {
+	if _, has := p.assigned[name]; has {
+		return expand.Variable{Kind: expand.Unset}
+	}
+
+	if _, has := p.assigned[positionalParams]; has && positional(name) {
+		return expand.Variable{Kind: expand.Unset}
+	}
+
+	switch name {
+	case "@", "*":
+		return expand.Variable{Kind: expand.Indexed, List: p.args}
+	case "#":
+		return expand.Variable{Kind: expand.String, Str: strconv.Itoa(len(p.args))}
+	}
+
+	if idx, err := strconv.Atoi(name); err == nil {
+		if idx < 1 || idx > len(p.args) {
+			return expand.Variable{Kind: expand.Unset}
+		}
+
+		return expand.Variable{Kind: expand.String, Str: p.args[idx-1]}
+	}
+
+	return p.env.Get(name)
+}
+
+func (p *paramEnviron) Each(fn func(name string, vr expand.Variable) bool) {
+	p.env.Each(fn)
+}
+
+func positional(name string) bool {
+	if name == "@" || name == "*" || name == "#" {
+		return true
+	}
+
+	idx, err := strconv.Atoi(name)
+
+	return err == nil && idx > 0
+}
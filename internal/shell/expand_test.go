@@ -0,0 +1,60 @@
+package shell_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/expand"
+)
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	env := expand.ListEnviron("HOME=/home/user", "NAME=a b")
+	args := []string{"x y", "z"}
+
+	tests := []struct {
+		name   string
+		before string
+		script string
+		want   string
+	}{
+		{"variables", "", `echo "$HOME" $NAME ${HOME}/bin`, `echo "/home/user" 'a b' /home/user/bin`},
+		{"positionals", "", `echo "$1" $2 "$@" $# "$*"`, `echo "x y" z 'x y' z 2 'x y z'`},
+		{"unknown", "", `echo "$OTHER" $3`, `echo "$OTHER" $3`},
+		{"assigned", "", `HOME=/override; echo "$HOME" "$NAME"`, "HOME=/override\necho \"$HOME\" \"a b\""},
+		{"assigned later", "", `echo "$HOME"; HOME=/override`, "echo \"$HOME\"\nHOME=/override"},
+		{"assigned before", "NAME=c", `echo "$NAME" "$HOME"`, `echo "$NAME" "/home/user"`},
+		{"loop", "", `for NAME in 1 2; do echo "$NAME"; done`, `for NAME in 1 2; do echo "$NAME"; done`},
+		{"read", "", `read -r NAME; echo "$NAME"`, "read -r NAME\necho \"$NAME\""},
+		{"set", "", `set -- q; echo "$1" "$@" $#`, "set -- q\necho \"$1\" \"$@\" $#"},
+		{"shift before", "shift", `echo "$1"`, `echo "$1"`},
+		{"set options", "", `set -euo pipefail; echo "$1"`, "set -euo pipefail\necho \"x y\""},
+		{
+			"function", "", `f() { echo "$1" "$@" "$HOME"; }; echo "$1"`,
+			"f() { echo \"$1\" \"$@\" \"/home/user\"; }\necho \"x y\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var before []*task.Step
+			if len(tt.before) != 0 {
+				before = append(before, &task.Step{Lang: "bash", Script: []byte(tt.before)})
+			}
+
+			got, err := shell.Expand(&task.Step{Lang: "bash", Script: []byte(tt.script)}, before, args, env)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.TrimSpace(string(got)) != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
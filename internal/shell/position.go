@@ -0,0 +1,63 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/interp"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// StatusError is a non-zero exit status of a statement, with the location of the statement in the task definition file.
+// The exit status remains available for interp.IsExitStatus.
+type StatusError struct {
+	Status uint8
+	File   string
+	Line   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("command exited with status %d at %s", e.Status, location(e.File, e.Line))
+}
+
+func (e *StatusError) Unwrap() error {
+	return interp.NewExitStatus(e.Status)
+}
+
+// locate maps the position of the shell syntax error from the script of the step to the task definition file.
+func locate(step *task.Step, err error) error {
+	var perr syntax.ParseError
+
+	if errors.As(err, &perr) {
+		perr.Filename = step.File
+		perr.Pos = position(step, perr.Pos)
+
+		return perr
+	}
+
+	var lerr syntax.LangError
+
+	if errors.As(err, &lerr) {
+		lerr.Filename = step.File
+		lerr.Pos = position(step, lerr.Pos)
+
+		return lerr
+	}
+
+	return err
+}
+
+func position(step *task.Step, pos syntax.Pos) syntax.Pos {
+	line, col := step.Position(int(pos.Line()), int(pos.Col())) //nolint:gosec
+
+	return syntax.NewPos(pos.Offset(), uint(line), uint(col)) //nolint:gosec
+}
+
+func location(file string, line int) string {
+	if len(file) == 0 {
+		return fmt.Sprintf("line %d", line)
+	}
+
+	return fmt.Sprintf("%s:%d", file, line)
+}
@@ -0,0 +1,204 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/interp"
+)
+
+const exitNotFound = 127
+
+// processes executes the external commands. Unlike the default exec handler of the interpreter,
+// the command is started in its own process group (where supported), which owns the terminal while it runs.
+// When the context is canceled, the received signal (or interrupt) is sent to the process group,
+// and the group is killed after the grace period, so no child process is left behind.
+type processes struct {
+	grace time.Duration
+	wg    sync.WaitGroup
+}
+
+// wait waits until all the started commands are finished.
+func (p *processes) wait() {
+	p.wg.Wait()
+}
+
+func (p *processes) handler(_ interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+	return func(ctx context.Context, args []string) error {
+		p.wg.Add(1)
+		defer p.wg.Done()
+
+		hc := interp.HandlerCtx(ctx)
+
+		path, err := interp.LookPathDir(hc.Dir, hc.Env, args[0])
+		if err != nil {
+			fmt.Fprintln(hc.Stderr, err)
+
+			return interp.NewExitStatus(exitNotFound)
+		}
+
+		cmd := &exec.Cmd{
+			Path:   path,
+			Args:   args,
+			Env:    execEnv(hc.Env),
+			Dir:    hc.Dir,
+			Stdin:  hc.Stdin,
+			Stdout: hc.Stdout,
+			Stderr: hc.Stderr,
+		}
+
+		owner, err := startProcess(cmd)
+		if err != nil {
+			fmt.Fprintln(hc.Stderr, err)
+
+			return interp.NewExitStatus(exitNotFound)
+		}
+
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+
+		go func() {
+			defer close(stopped)
+
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+			}
+
+			_ = signalProcess(cmd.Process, signalOf(ctx))
+
+			select {
+			case <-done:
+			case <-time.After(p.grace):
+			}
+
+			_ = killProcess(cmd.Process)
+		}()
+
+		err = cmd.Wait()
+
+		close(done)
+		<-stopped
+
+		releaseProcess(cmd)
+		raiseInterrupt(cmd, owner)
+
+		return exitStatus(ctx, err)
+	}
+}
+
+func exitStatus(ctx context.Context, err error) error {
+	var exitErr *exec.ExitError
+
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return interp.NewExitStatus(uint8(128 + status.Signal())) //nolint:gosec
+	}
+
+	return interp.NewExitStatus(uint8(exitErr.ExitCode())) //nolint:gosec
+}
+
+// execEnv returns the exported variables in name=value format.
+func execEnv(env expand.Environ) []string {
+	list := make([]string, 0)
+
+	env.Each(func(name string, vr expand.Variable) bool {
+		if !vr.IsSet() {
+			// the variable is unset in the session, remove it from the list
+			for i, kv := range list {
+				if strings.HasPrefix(kv, name+"=") {
+					list[i] = ""
+				}
+			}
+		}
+
+		if vr.Exported && vr.Kind == expand.String {
+			list = append(list, name+"="+vr.String())
+		}
+
+		return true
+	})
+
+	return list
+}
+
+// Interrupted is the cause of the context cancellation when a signal is received.
+type Interrupted struct {
+	Signal os.Signal
+}
+
+func (i *Interrupted) Error() string {
+	return "interrupted by " + i.Signal.String()
+}
+
+func signalOf(ctx context.Context) os.Signal {
+	var intr *Interrupted
+
+	if errors.As(context.Cause(ctx), &intr) {
+		return intr.Signal
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return syscall.SIGTERM
+	}
+
+	return os.Interrupt
+}
+
+// NotifyContext returns a context that is canceled when an interrupt (SIGINT)
+// or termination (SIGTERM) signal is received. The cause of the cancellation will be an *Interrupted error.
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signals := make(chan os.Signal, 1)
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			cancel(&Interrupted{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(context.Canceled)
+	}
+}
+
+// InterruptStatus returns the conventional exit status (128 + signal number)
+// if the context was canceled because of a signal.
+func InterruptStatus(ctx context.Context) (int, bool) {
+	var intr *Interrupted
+
+	if !errors.As(context.Cause(ctx), &intr) {
+		return 0, false
+	}
+
+	const signalBase = 128
+
+	if sig, ok := intr.Signal.(syscall.Signal); ok {
+		return signalBase + int(sig), true
+	}
+
+	return signalBase + int(syscall.SIGINT), true
+}
@@ -0,0 +1,125 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// terminal is the controlling terminal of cdo, nil if there is none.
+//
+//nolint:gochecknoglobals
+var terminal = sync.OnceValue(func() *os.File {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil
+	}
+
+	return tty
+})
+
+// foreground is the process group owning the terminal instead of cdo, 0 if there is none.
+// The mutex serializes the starting of the commands and the moving of the terminal between the process groups.
+//
+//nolint:gochecknoglobals
+var foreground struct {
+	sync.Mutex
+	pgid int
+}
+
+// startProcess starts the command in its own process group. If cdo is in the foreground process group
+// of its controlling terminal, and no other command owns the terminal, the new group is moved to the foreground,
+// so the command can read from the terminal (a background process group would be stopped by SIGTTIN)
+// and it receives the signals of the terminal. It reports whether the command owns the terminal.
+func startProcess(cmd *exec.Cmd) (bool, error) {
+	foreground.Lock()
+	defer foreground.Unlock()
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	tty := terminal()
+	owner := foreground.pgid == 0 && tty != nil && getpgrp(tty) == syscall.Getpgrp()
+
+	if owner {
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = int(tty.Fd())
+	}
+
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+
+	if owner {
+		foreground.pgid = cmd.Process.Pid
+	}
+
+	return owner, nil
+}
+
+// releaseProcess moves the terminal back to the process group of cdo after the command owning it has finished.
+func releaseProcess(cmd *exec.Cmd) {
+	foreground.Lock()
+	defer foreground.Unlock()
+
+	if foreground.pgid != cmd.Process.Pid {
+		return
+	}
+
+	foreground.pgid = 0
+
+	tty := terminal()
+	if getpgrp(tty) != cmd.Process.Pid {
+		return
+	}
+
+	// cdo is in a background process group now, so setting the foreground group would stop it by SIGTTOU
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+
+	pgid := int32(syscall.Getpgrp()) //nolint:gosec
+
+	_, _, _ = syscall.Syscall(syscall.SYS_IOCTL, tty.Fd(), syscall.TIOCSPGRP, uintptr(unsafe.Pointer(&pgid)))
+}
+
+// getpgrp returns the foreground process group of the terminal.
+func getpgrp(tty *os.File) int {
+	var pgid int32
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, tty.Fd(), syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&pgid)))
+	if errno != 0 {
+		return -1
+	}
+
+	return int(pgid)
+}
+
+// raiseInterrupt raises the interrupt in cdo if the command owning the terminal was interrupted from the terminal.
+// Like shells do, so the other commands are stopped as well.
+func raiseInterrupt(cmd *exec.Cmd, owner bool) {
+	if !owner || cmd.ProcessState == nil {
+		return
+	}
+
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() && status.Signal() == syscall.SIGINT {
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}
+}
+
+func signalProcess(proc *os.Process, sig os.Signal) error {
+	num, ok := sig.(syscall.Signal)
+	if !ok {
+		num = syscall.SIGINT
+	}
+
+	return syscall.Kill(-proc.Pid, num)
+}
+
+func killProcess(proc *os.Process) error {
+	return syscall.Kill(-proc.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+)
+
+func startProcess(cmd *exec.Cmd) (bool, error) {
+	return false, cmd.Start()
+}
+
+func releaseProcess(_ *exec.Cmd) {}
+
+func raiseInterrupt(_ *exec.Cmd, _ bool) {}
+
+// signalProcess kills the process, because signals cannot be sent to processes on Windows.
+func signalProcess(proc *os.Process, _ os.Signal) error {
+	return proc.Kill()
+}
+
+func killProcess(proc *os.Process) error {
+	return proc.Kill()
+}
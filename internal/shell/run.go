@@ -3,24 +3,296 @@ package shell
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/szkiba/cdo/internal/environ"
+	"github.com/szkiba/cdo/internal/task"
 	"mvdan.cc/sh/v3/interp"
 	"mvdan.cc/sh/v3/syntax"
 )
 
-func Run(task string, args []string, script []byte, dir string, env environ.Environ) error {
-	file, _ := syntax.NewParser().Parse(bytes.NewReader(script), task)
+const appname = "cdo"
+
+// Options contains the settings of the shell session.
+type Options struct {
+	// Dir is the working directory.
+	Dir string
+	// Env contains the variables of the session.
+	Env environ.Environ
+	// GracePeriod is the time the commands get to exit after the interrupt signal before they are killed.
+	GracePeriod time.Duration
+	// Stdin, Stdout and Stderr are the standard streams of the session.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Utils is the precedence of the sources of the non-shell built-in commands (DefaultUtils if nil).
+	Utils []string
+	// Busybox is the busybox usage mode (BusyboxAuto if empty, BusyboxAlways, BusyboxNever or the path of busybox).
+	Busybox string
+	// Command runs the cdo commands of the scripts in-process (optional).
+	Command CommandFunc
+}
+
+// Run executes the steps of the task in a single shell session,
+// so variables and functions defined in a step are available in the following steps.
+// Steps not supported on the current operating system are skipped.
+//
+// When the session ends, the commands still running in the background are interrupted.
+func Run(ctx context.Context, name string, args []string, steps []*task.Step, opts *Options) error {
+	sess := &session{name: name, dir: opts.Dir, base: opts.Dir, parser: syntax.NewParser()}
+	procs := &processes{grace: opts.GracePeriod}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	defer procs.wait()
+	defer cancel()
+
 	params := []string{"-e", "--"}
 	params = append(params, args...)
-	runner, _ := interp.New(
-		interp.StdIO(os.Stdin, os.Stdout, os.Stdout),
+
+	utils, err := utilsHandler(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	runner, err := interp.New(
+		interp.StdIO(opts.Stdin, opts.Stdout, opts.Stderr),
 		interp.Params(params...),
-		interp.Env(env),
-		interp.Dir(dir),
-		interp.ExecHandlers(busyBoxHandler(dir, env)),
+		interp.Env(opts.Env),
+		interp.Dir(opts.Dir),
+		interp.ExecHandlers(commandHandler(opts.Command), utils, procs.handler),
 	)
+	if err != nil {
+		return err
+	}
+
+	sess.runner = runner
+
+	// the scripts are parsed before running anything, so a syntax error doesn't leave the task half done
+	files := make([]*syntax.File, len(steps))
+
+	for idx, step := range steps {
+		if step.Embedded() && step.Supports(runtime.GOOS) {
+			if files[idx], err = parse(step); err != nil {
+				return err
+			}
+		}
+	}
+
+	for idx, step := range steps {
+		if !step.Supports(runtime.GOOS) {
+			continue
+		}
+
+		if err := sess.step(ctx, step, files[idx]); err != nil || runner.Exited() {
+			return err
+		}
+	}
+
+	// running an empty file executes the exit trap and returns the last exit status
+	return runner.Run(ctx, &syntax.File{Name: name})
+}
+
+// parse parses the script of the step, the position of the syntax error is reported in the task definition file.
+func parse(step *task.Step) (*syntax.File, error) {
+	file, err := syntax.NewParser().Parse(bytes.NewReader(step.Script), step.File)
+	if err != nil {
+		return nil, locate(step, err)
+	}
+
+	return file, nil
+}
+
+type session struct {
+	name    string
+	dir     string
+	parser  *syntax.Parser
+	runner  *interp.Runner
+	current *task.Step
+	// base is the base directory of the current step
+	base string
+}
+
+func (s *session) parse(script string) (*syntax.File, error) {
+	return s.parser.Parse(strings.NewReader(script), s.name)
+}
+
+func (s *session) step(ctx context.Context, step *task.Step, file *syntax.File) error {
+	s.current = step
+
+	if err := s.enter(ctx, step); err != nil || s.runner.Exited() {
+		return err
+	}
+
+	if !step.Embedded() {
+		var (
+			cleanup func()
+			err     error
+		)
+
+		if file, cleanup, err = s.load(step); err != nil {
+			return err
+		}
+
+		defer cleanup()
+	}
+
+	if len(step.If) != 0 {
+		ok, err := s.test(ctx, step.If)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	if len(step.Dir) == 0 {
+		return s.run(ctx, file)
+	}
+
+	prev := s.runner.Dir
+
+	dir := step.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.base, dir)
+	}
+
+	if err := s.chdir(ctx, dir); err != nil || s.runner.Exited() {
+		return err
+	}
+
+	if err := s.run(ctx, file); err != nil || s.runner.Exited() {
+		return err
+	}
+
+	return s.chdir(ctx, prev)
+}
+
+// load returns the shell program of a step with interpreter. The script is written to a temporary file,
+// and the program invokes the interpreter with the file name and the positional arguments.
+func (s *session) load(step *task.Step) (*syntax.File, func(), error) {
+	tmp, err := os.CreateTemp("", appname+"-*"+step.Extension())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() { _ = os.Remove(tmp.Name()) }
+
+	_, err = tmp.Write(step.Script)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	words := make([]string, 0, len(step.Interpreter)+2)
+
+	for _, word := range append(slices.Clone(step.Interpreter), tmp.Name()) {
+		quoted, err := syntax.Quote(word, syntax.LangBash)
+		if err != nil {
+			cleanup()
+
+			return nil, nil, err
+		}
+
+		words = append(words, quoted)
+	}
+
+	words = append(words, `"$@"`)
+
+	file, err := s.parse(strings.Join(words, " "))
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	return file, cleanup, nil
+}
+
+// enter changes the working directory to the base directory of the step,
+// if it differs from the base directory of the previous step (the step comes from another file).
+func (s *session) enter(ctx context.Context, step *task.Step) error {
+	base := s.dir
+	if len(step.Base) != 0 {
+		base = step.Base
+	}
+
+	if base == s.base {
+		return nil
+	}
 
-	return runner.Run(context.TODO(), file)
+	s.base = base
+
+	absdir, err := filepath.Abs(base)
+	if err != nil {
+		return err
+	}
+
+	return s.chdir(ctx, absdir)
 }
+
+func (s *session) chdir(ctx context.Context, dir string) error {
+	quoted, err := syntax.Quote(dir, syntax.LangBash)
+	if err != nil {
+		return err
+	}
+
+	file, err := s.parse("cd " + quoted)
+	if err != nil {
+		return err
+	}
+
+	return s.run(ctx, file)
+}
+
+// run executes the statements one by one. The statements are not executed as a file,
+// because the end of the file would terminate the shell session.
+// A non-zero exit status terminating the session is reported with the location of the statement.
+func (s *session) run(ctx context.Context, file *syntax.File) error {
+	for _, stmt := range file.Stmts {
+		err := s.runner.Run(ctx, stmt)
+		if s.runner.Exited() {
+			if status, ok := interp.IsExitStatus(err); ok && status != 0 {
+				line, _ := s.current.Position(int(stmt.Pos().Line()), 0) //nolint:gosec
+
+				return &StatusError{Status: status, File: s.current.File, Line: line}
+			}
+
+			return err
+		}
+
+		if _, ok := interp.IsExitStatus(err); err != nil && !ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// test evaluates the condition without terminating the shell session on failure.
+func (s *session) test(ctx context.Context, cond string) (bool, error) {
+	file, err := s.parse(fmt.Sprintf("{\n%s\n} && :", cond))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w: %s", location(s.current.File, s.current.Line), errInvalidCondition, cond)
+	}
+
+	err = s.runner.Run(ctx, file.Stmts[0])
+	if _, ok := interp.IsExitStatus(err); ok {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+var errInvalidCondition = errors.New("invalid condition")
@@ -0,0 +1,129 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/coreutils"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// The sources of the non-shell built-in commands.
+const (
+	// UtilsBusybox runs the command as subcommand of busybox, if busybox supports it.
+	UtilsBusybox = "busybox"
+	// UtilsNative runs the command found in the search path.
+	UtilsNative = "native"
+	// UtilsBuiltin runs the portable implementation embedded in cdo.
+	UtilsBuiltin = "builtin"
+)
+
+// DefaultUtils returns the default precedence of the command sources.
+// On Windows the builtin utilities come first, because the native find and sort commands are not POSIX compatible.
+func DefaultUtils() []string {
+	if runtime.GOOS == "windows" {
+		return []string{UtilsBusybox, UtilsBuiltin, UtilsNative}
+	}
+
+	return []string{UtilsBusybox, UtilsNative, UtilsBuiltin}
+}
+
+// utilsHandler runs the commands from the first source supporting them, in the order of precedence.
+// If none of them does, the command is passed to the next handler.
+func utilsHandler(
+	ctx context.Context, opts *Options,
+) (func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc, error) {
+	utils := opts.Utils
+	if utils == nil {
+		utils = DefaultUtils()
+	}
+
+	box, err := findBusybox(ctx, opts.Dir, opts.Env, opts.Busybox)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+		var handler interp.ExecHandlerFunc
+
+		handler = func(ctx context.Context, args []string) error {
+			for _, source := range utils {
+				switch source {
+				case UtilsBusybox:
+					if box.supports(args[0]) {
+						return next(ctx, box.command(args))
+					}
+				case UtilsNative:
+					hc := interp.HandlerCtx(ctx)
+					if _, err := interp.LookPathDir(hc.Dir, hc.Env, args[0]); err == nil {
+						return next(ctx, args)
+					}
+				case UtilsBuiltin:
+					if cmd, found := coreutils.Lookup(args[0]); found {
+						return builtin(ctx, cmd, args, handler)
+					}
+				}
+			}
+
+			return next(ctx, args)
+		}
+
+		return handler
+	}, nil
+}
+
+// builtin runs the embedded utility, the commands started by the utility (like xargs) are run by the handler.
+func builtin(ctx context.Context, cmd coreutils.Command, args []string, handler interp.ExecHandlerFunc) error {
+	hc := interp.HandlerCtx(ctx)
+
+	err := cmd(ctx, &coreutils.Env{
+		Dir:    hc.Dir,
+		Stdin:  hc.Stdin,
+		Stdout: hc.Stdout,
+		Stderr: hc.Stderr,
+		Exec:   handler,
+	}, args[1:])
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	var status coreutils.Status
+	if errors.As(err, &status) {
+		return interp.NewExitStatus(uint8(status))
+	}
+
+	if _, ok := interp.IsExitStatus(err); ok {
+		return err
+	}
+
+	// the file names are reported relative to the working directory, like the native commands do
+	var (
+		perr *fs.PathError
+		lerr *os.LinkError
+	)
+
+	if errors.As(err, &perr) {
+		err = fmt.Errorf("%s: %w", relative(hc.Dir, perr.Path), perr.Err)
+	} else if errors.As(err, &lerr) {
+		err = fmt.Errorf("%s %s: %w", relative(hc.Dir, lerr.Old), relative(hc.Dir, lerr.New), lerr.Err)
+	}
+
+	fmt.Fprintf(hc.Stderr, "%s: %s\n", args[0], err)
+
+	return interp.NewExitStatus(1)
+}
+
+// relative returns the file name relative to the directory, if it is inside the directory.
+func relative(dir, name string) string {
+	if rel, err := filepath.Rel(dir, name); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return name
+}
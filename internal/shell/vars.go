@@ -0,0 +1,138 @@
+package shell
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/task"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Vars returns the names of the variables referenced but not assigned in the steps.
+// These are the variables that can be passed to the task as name=value parameters.
+func Vars(steps []*task.Step) []string {
+	referenced := make(map[string]struct{})
+	assigned := make(map[string]struct{})
+	parser := syntax.NewParser()
+
+	for _, step := range steps {
+		if !step.Embedded() {
+			continue
+		}
+
+		file, err := parser.Parse(bytes.NewReader(step.Script), "")
+		if err != nil {
+			continue
+		}
+
+		syntax.Walk(file, func(node syntax.Node) bool {
+			if param, ok := node.(*syntax.ParamExp); ok && syntax.ValidName(param.Param.Value) {
+				referenced[param.Param.Value] = struct{}{}
+			}
+
+			assignments(node, func(name string) { assigned[name] = struct{}{} })
+
+			return true
+		})
+	}
+
+	names := make([]string, 0, len(referenced))
+
+	for name := range referenced {
+		if _, has := assigned[name]; !has {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// positionalParams is the name reported by assignments when the positional parameters are changed (set, shift).
+const positionalParams = "@"
+
+// assignments calls fn with the name of each variable assigned (or unset) by the node: assignments,
+// loop variables, the variables of read like builtins and the arithmetic and ${name:=value} assignments.
+func assignments(node syntax.Node, fn func(name string)) {
+	switch node := node.(type) {
+	case *syntax.Assign:
+		if node.Name != nil {
+			fn(node.Name.Value)
+		}
+	case *syntax.WordIter:
+		fn(node.Name.Value)
+	case *syntax.ParamExp:
+		if node.Exp != nil && (node.Exp.Op == syntax.AssignUnset || node.Exp.Op == syntax.AssignUnsetOrNull) {
+			fn(node.Param.Value)
+		}
+	case *syntax.UnaryArithm:
+		if node.Op == syntax.Inc || node.Op == syntax.Dec {
+			arithmName(node.X, fn)
+		}
+	case *syntax.BinaryArithm:
+		if slices.Contains(arithmAssigns, node.Op) {
+			arithmName(node.X, fn)
+		}
+	case *syntax.CallExpr:
+		callAssignments(node, fn)
+	}
+}
+
+//nolint:gochecknoglobals
+var arithmAssigns = []syntax.BinAritOperator{
+	syntax.Assgn, syntax.AddAssgn, syntax.SubAssgn, syntax.MulAssgn, syntax.QuoAssgn, syntax.RemAssgn,
+	syntax.AndAssgn, syntax.OrAssgn, syntax.XorAssgn, syntax.ShlAssgn, syntax.ShrAssgn,
+}
+
+func arithmName(expr syntax.ArithmExpr, fn func(name string)) {
+	if word, ok := expr.(*syntax.Word); ok && syntax.ValidName(word.Lit()) {
+		fn(word.Lit())
+	}
+}
+
+// callAssignments reports the variables assigned by the builtins. The arguments of the options are not known,
+// so every literal argument that is a valid name is reported.
+func callAssignments(call *syntax.CallExpr, fn func(name string)) {
+	if len(call.Args) == 0 {
+		return
+	}
+
+	args := make([]string, 0, len(call.Args)-1)
+	for _, arg := range call.Args[1:] {
+		args = append(args, arg.Lit())
+	}
+
+	switch call.Args[0].Lit() {
+	case "read", "mapfile", "readarray", "unset", "getopts":
+		for _, arg := range args {
+			if syntax.ValidName(arg) {
+				fn(arg)
+			}
+		}
+	case "printf":
+		if idx := slices.Index(args, "-v"); idx >= 0 && idx+1 < len(args) && syntax.ValidName(args[idx+1]) {
+			fn(args[idx+1])
+		}
+	case "set":
+		setAssignments(args, fn)
+	case "shift":
+		fn(positionalParams)
+	}
+}
+
+// setAssignments reports the positional parameters if the set builtin changes them.
+func setAssignments(args []string, fn func(name string)) {
+	for idx := 0; idx < len(args); idx++ {
+		switch arg := args[idx]; {
+		case arg == "--", !strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "+"):
+			fn(positionalParams)
+
+			return
+		case strings.HasSuffix(arg, "o"): // the next argument is the name of the option
+			idx++
+		}
+	}
+}
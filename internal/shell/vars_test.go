@@ -0,0 +1,41 @@
+package shell_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/shell"
+	"github.com/szkiba/cdo/internal/task"
+)
+
+func TestVars(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"referenced", `echo "$NAME" ${COUNT:-1} $1 $@`, []string{"COUNT", "NAME"}},
+		{"assigned", "NAME=x; export OTHER=y; echo $NAME $OTHER $REST", []string{"REST"}},
+		{"declared", "local name; declare -a list; echo $name ${list[0]}", []string{}},
+		{"loop", "for f in *.go; do echo $f $DIR; done", []string{"DIR"}},
+		{"read", "read -r -p prompt line rest; echo $line $rest", []string{}},
+		{"read loop", `while IFS= read -r line; do echo "$line"; done < "$IN"`, []string{"IN"}},
+		{"printf", `printf -v out '%s' "$IN"; echo "$out"`, []string{"IN"}},
+		{"arithmetic", "(( count++ )); (( total += count )); echo $count $total", []string{}},
+		{"default assignment", `: "${LEVEL:=info}"; echo "$LEVEL"`, []string{}},
+		{"unset", "unset TMP; echo $TMP", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := shell.Vars([]*task.Step{{Lang: "bash", Script: []byte(tt.script)}})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Vars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/google/shlex"
 	"github.com/iancoleman/strcase"
 	"github.com/yuin/goldmark/ast"
 	east "github.com/yuin/goldmark/extension/ast"
@@ -13,20 +15,51 @@ import (
 )
 
 type builder struct {
-	tasks      []*Task
+	filename   string
+	dir        string
+	drafts     []*draft
 	task       *Task
 	level      int
 	source     []byte
 	startIndex int
 	endIndex   int
+	blocks     []*block
+	parents    []*parent
 	term       string
-	options    map[string]string
+	termLine   int
+	includes   []*include
+	options    map[string][]string
+	globals    map[string][]string
+	all        []*Task
+	errs       []*Problem
+	warns      []*Problem
 }
 
-func newBuilder(source []byte) *builder {
+// draft is a task whose code blocks are not yet converted to steps.
+type draft struct {
+	task       *Task
+	startIndex int
+	endIndex   int
+	blocks     []*block
+	options    map[string][]string
+	parent     bool
+}
+
+// block is a fenced code block with language inside a task definition.
+type block struct {
+	fcb   *ast.FencedCodeBlock
+	lang  string
+	attrs string
+	start int
+	end   int
+}
+
+func newBuilder(filename string, source []byte) *builder {
 	b := new(builder)
 
+	b.filename = filename
 	b.source = source
+	b.globals = make(map[string][]string)
 
 	return b
 }
@@ -36,46 +69,168 @@ func (b *builder) add() {
 		return
 	}
 
-	getopts(b.task, b.options)
-
-	b.tasks = append(b.tasks, b.task)
+	b.drafts = append(b.drafts, &draft{
+		task:       b.task,
+		startIndex: b.startIndex,
+		endIndex:   b.endIndex,
+		blocks:     b.blocks,
+		options:    b.options,
+	})
 
 	b.task = nil
 	b.options = nil
+	b.blocks = nil
 }
 
-func (b *builder) build() (map[string]*Task, error) {
+// parent is a task heading declaring Namespace and containing other task headings,
+// the name of the parent is the namespace of the tasks in its section.
+type parent struct {
+	task  *Task
+	level int
+}
+
+// declaresNamespace reports whether the task options contain the Namespace term (with a value other than false).
+func declaresNamespace(opts map[string][]string) bool {
+	values, has := lookupOption(opts, "namespace")
+
+	return has && !strings.EqualFold(strings.TrimSpace(values[len(values)-1]), "false")
+}
+
+// include is a file included by the Include definition list term.
+type include struct {
+	path string
+	line int
+}
+
+// build finishes the drafts and adds the tasks to the task map. The errors and the suspicious constructs (warnings)
+// are collected as problems, the tasks with errors are left out.
+func (b *builder) build(tasks map[string]*Task) {
+	b.endIndex = len(b.source)
 	b.add()
 
-	tasks := make(map[string]*Task, len(b.tasks))
+	interpreters := defaultInterpreters()
 
-	for _, task := range b.tasks {
-		tasks[task.Name] = task
+	if values, has := lookupOption(b.globals, "interpreters"); has {
+		for lang, command := range parseInterpreters(strings.Join(values, ",")) {
+			interpreters[lang] = command
+		}
 	}
 
-	lookup := func(name string) (bool, [][]string) {
-		task, has := tasks[name]
+	var namespace string
+
+	if values, has := lookupOption(b.globals, "namespace"); has {
+		namespace = strcase.ToKebab(strings.TrimSpace(values[len(values)-1]))
+	}
+
+	for _, draft := range b.drafts {
+		task := draft.task
+		task.File = b.filename
+		task.Dir = b.dir
+
+		if len(namespace) != 0 {
+			task.Name = qualify(namespace, task.Name)
+
+			if len(task.Namespace) == 0 {
+				task.Namespace = namespace
+			} else {
+				task.Namespace = qualify(namespace, task.Namespace)
+			}
+		}
+
+		if err := b.finish(draft, interpreters); err != nil {
+			b.errs = append(b.errs, b.problem(task.Line, err))
+
+			continue
+		}
+
+		if len(task.Name) == 0 {
+			b.errs = append(b.errs, b.problem(task.Line, errNoName))
+
+			continue
+		}
+
+		if len(task.Steps) == 0 && len(task.Requires) == 0 && !draft.parent {
+			b.warns = append(b.warns, b.problem(task.Line, fmt.Errorf("%w: %s", errEmptyTask, task.Name)))
+		}
+
+		b.all = append(b.all, task)
+
+		prev, has := tasks[task.Name]
 		if !has {
-			return false, nil
+			if len(task.override) != 0 {
+				b.warns = append(b.warns, b.problem(task.Line, fmt.Errorf("%w: %s", errNothingToOverride, task.Name)))
+			}
+
+			tasks[task.Name] = task
+
+			continue
+		}
+
+		merged, err := override(prev, task)
+		if err != nil {
+			b.errs = append(b.errs, b.problem(task.Line, err))
+
+			continue
 		}
 
-		return true, task.Requires
+		tasks[task.Name] = merged
 	}
+}
 
-	for _, task := range b.tasks {
-		for _, req := range task.Requires {
-			visited := map[string]struct{}{task.Name: {}}
-			if err := checkdep(req[0], lookup, visited); err != nil {
-				return nil, err
-			}
+func (b *builder) problem(line int, err error) *Problem {
+	return &Problem{File: b.filename, Line: line, Err: err}
+}
+
+// finish converts the runnable code blocks of the draft to steps
+// and collects the prose between them as the long description.
+func (b *builder) finish(draft *draft, interpreters map[string][]string) error {
+	if err := getopts(draft.task, draft.options); err != nil {
+		return err
+	}
+
+	var prose [][]byte
+
+	startIndex := draft.startIndex
+
+	for _, block := range draft.blocks {
+		found, step, err := extractStep(block, b.source, interpreters)
+		if err != nil {
+			return err
 		}
+
+		if !found {
+			continue
+		}
+
+		step.File = b.filename
+		step.Base = b.dir
+		draft.task.Steps = append(draft.task.Steps, step)
+
+		if text := bytes.TrimSpace(b.source[startIndex:block.start]); len(text) != 0 {
+			prose = append(prose, text)
+		}
+
+		startIndex = block.end
+	}
+
+	// the prose (and the documentation-only code blocks) after the last step
+	if draft.endIndex > startIndex {
+		if text := bytes.TrimSpace(b.source[startIndex:draft.endIndex]); len(text) != 0 {
+			prose = append(prose, text)
+		}
+	}
+
+	if len(draft.task.Steps) != 0 {
+		draft.task.Long = string(bytes.Join(prose, []byte("\n\n")))
 	}
 
-	return tasks, nil
+	return nil
 }
 
+// checkdep checks the dependencies of the task recursively. The visited set contains the tasks
+// of the current dependency chain only, so a task required on several paths (diamond) is not a cycle.
 func checkdep(name string, lookup func(string) (bool, [][]string), visited map[string]struct{}) error {
-	if _, done := visited[name]; done {
+	if _, cycle := visited[name]; cycle {
 		return fmt.Errorf("%w: %s", errRequiresCycle, name)
 	}
 
@@ -85,6 +240,7 @@ func checkdep(name string, lookup func(string) (bool, [][]string), visited map[s
 	}
 
 	visited[name] = struct{}{}
+	defer delete(visited, name)
 
 	for _, dep := range deps {
 		if err := checkdep(dep[0], lookup, visited); err != nil {
@@ -116,37 +272,86 @@ func (b *builder) handleDefinitionList(node ast.Node, entering bool) {
 		return
 	}
 
+	// a definition term can have more than one definition description
 	if desc := asDefinitionDescription(node, entering); desc != nil {
 		if len(b.term) != 0 {
-			b.options[b.term] = string(desc.Text(b.source))
+			opts := b.globals
+			if b.task != nil {
+				opts = b.options
+			} else if strings.EqualFold(b.term, "include") {
+				b.addIncludes(string(desc.Text(b.source)))
+			}
 
-			b.term = ""
+			opts[b.term] = append(opts[b.term], string(desc.Text(b.source)))
 		}
 
 		return
 	}
 
-	if term := asDefinitionTerm(node, entering); term != nil && b.task != nil {
+	if term := asDefinitionTerm(node, entering); term != nil {
 		b.term = string(term.Text(b.source))
+
+		if lines := term.Lines(); lines.Len() != 0 {
+			b.termLine = lineOf(b.source, lines.At(0).Start)
+		}
+	}
+}
+
+func (b *builder) addIncludes(value string) {
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); len(path) != 0 {
+			b.includes = append(b.includes, &include{path: path, line: b.termLine})
+		}
 	}
 }
 
 func (b *builder) handleHeading(node ast.Node, entering bool) bool {
 	heading := asHeading(node, entering)
 	if heading != nil {
+		// the section of the current task ends before the heading (if the heading ends it)
+		if lines := heading.Lines(); lines.Len() != 0 {
+			b.endIndex = lineStart(b.source, lines.At(0).Start)
+		}
+
 		if heading.Level <= b.level {
 			b.add()
 		}
 
+		for len(b.parents) != 0 && heading.Level <= b.parents[len(b.parents)-1].level {
+			b.parents = b.parents[:len(b.parents)-1]
+		}
+
 		contents := extractBlock(heading.Lines(), b.source)
 
 		match, name, short := extractNameShort(contents)
 		if match {
+			// a task heading inside the section of a task declaring Namespace makes that task a namespace
+			nested := b.task != nil && heading.Level > b.level && declaresNamespace(b.options)
+			if nested {
+				b.parents = append(b.parents, &parent{task: b.task, level: b.level})
+			}
+
 			b.add()
-			b.task = &Task{Name: name, Short: short}
+
+			if nested {
+				b.drafts[len(b.drafts)-1].parent = true
+			}
+
+			var namespace string
+
+			if len(b.parents) != 0 {
+				namespace = b.parents[len(b.parents)-1].task.Name
+			}
+
 			b.startIndex = heading.Lines().At(0).Start
+			b.task = &Task{
+				Name:      qualify(namespace, name),
+				Namespace: namespace,
+				Short:     short,
+				Line:      lineOf(b.source, b.startIndex),
+			}
 			b.level = heading.Level
-			b.options = make(map[string]string)
+			b.options = make(map[string][]string)
 		}
 
 		return true
@@ -155,35 +360,30 @@ func (b *builder) handleHeading(node ast.Node, entering bool) bool {
 	return false
 }
 
-func (b *builder) handleCodeBlock(node ast.Node, entering bool) error {
+func (b *builder) handleCodeBlock(node ast.Node, entering bool) {
 	if b.task == nil {
-		return nil
+		return
 	}
 
 	fcb := asFencedCodeBlock(node, entering)
 	if entering || fcb == nil || fcb.Info == nil {
-		return nil
+		return
 	}
 
 	const fencePrefixLen = 3
 
-	b.endIndex = fcb.Info.Segment.Start - fencePrefixLen
-
-	found, script, err := extractScript(fcb, b.source)
-	if !found || err != nil {
-		return err
+	lang, attrs := extractInfo(fcb, b.source)
+	if len(lang) == 0 {
+		return
 	}
 
-	b.task.Script = script
-
-	b.task.Long = string(bytes.TrimSpace(b.source[b.startIndex:b.endIndex]))
-
-	b.add()
-
-	b.task = nil
-	b.options = nil
-
-	return nil
+	b.blocks = append(b.blocks, &block{
+		fcb:   fcb,
+		lang:  lang,
+		attrs: attrs,
+		start: fcb.Info.Segment.Start - fencePrefixLen,
+		end:   blockEnd(fcb, b.source),
+	})
 }
 
 func (b *builder) walk(node ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -193,9 +393,14 @@ func (b *builder) walk(node ast.Node, entering bool) (ast.WalkStatus, error) {
 		return ast.WalkContinue, nil
 	}
 
-	err := b.handleCodeBlock(node, entering)
+	b.handleCodeBlock(node, entering)
+
+	return ast.WalkContinue, nil
+}
 
-	return ast.WalkContinue, err
+// lineStart returns the index of the beginning of the line containing the index.
+func lineStart(source []byte, index int) int {
+	return bytes.LastIndexByte(source[:index], '\n') + 1
 }
 
 var reInfo = regexp.MustCompile(`\s*(\w+)\s*(.*)\s*`)
@@ -226,17 +431,79 @@ func asFencedCodeBlock(node ast.Node, entering bool) *ast.FencedCodeBlock {
 	return nil
 }
 
-func extractScript(fcb *ast.FencedCodeBlock, source []byte) (bool, []byte, error) {
-	lang, err := extractInfo(fcb, source)
+func extractStep(block *block, source []byte, interpreters map[string][]string) (bool, *Step, error) {
+	interpreter, runnable := interpreters[block.lang]
+	if !runnable && block.lang != "bash" && block.lang != "sh" {
+		return false, nil, nil
+	}
+
+	attrs, err := parseAttrs(block.attrs)
 	if err != nil {
-		return false, nil, err
+		return false, nil, fmt.Errorf("%w: %s: %w", errInvalidInfo, block.fcb.Info.Text(source), err)
 	}
 
-	if lang != "bash" && lang != "sh" {
+	if value, has := attrs["ignore"]; has && value != "false" {
 		return false, nil, nil
 	}
 
-	return true, extractBlock(fcb.Lines(), source), nil
+	step := &Step{
+		Lang:        block.lang,
+		Script:      extractBlock(block.fcb.Lines(), source),
+		Interpreter: interpreter,
+		Attrs:       attrs,
+		Line:        lineOf(source, block.start),
+		Indent:      indentOf(block.fcb.Lines(), source),
+	}
+
+	step.Dir = attrs["dir"]
+	step.If = attrs["if"]
+
+	if value := attrs["os"]; len(value) != 0 {
+		step.OS = strings.Split(value, ",")
+	}
+
+	return true, step, nil
+}
+
+// blockEnd returns the index of the line following the closing fence of the code block.
+func blockEnd(fcb *ast.FencedCodeBlock, source []byte) int {
+	var idx int
+
+	if lines := fcb.Lines(); lines.Len() != 0 {
+		idx = lines.At(lines.Len() - 1).Stop
+	} else {
+		idx = nextLine(source, fcb.Info.Segment.Stop)
+	}
+
+	return nextLine(source, idx)
+}
+
+// lineOf returns the (1-based) line number of the index.
+func lineOf(source []byte, idx int) int {
+	return bytes.Count(source[:idx], []byte{'\n'}) + 1
+}
+
+// indentOf returns the number of bytes stripped from the beginning of the lines of the code block.
+func indentOf(lines *text.Segments, source []byte) int {
+	if lines.Len() == 0 {
+		return 0
+	}
+
+	start := lines.At(0).Start
+
+	return start - (bytes.LastIndexByte(source[:start], '\n') + 1)
+}
+
+func nextLine(source []byte, idx int) int {
+	if idx >= len(source) {
+		return len(source)
+	}
+
+	if eol := bytes.IndexByte(source[idx:], '\n'); eol >= 0 {
+		return idx + eol + 1
+	}
+
+	return len(source)
 }
 
 func extractBlock(lines *text.Segments, source []byte) []byte {
@@ -251,34 +518,43 @@ func extractBlock(lines *text.Segments, source []byte) []byte {
 	return buff.Bytes()
 }
 
-func extractInfo(fcb *ast.FencedCodeBlock, source []byte) (string, error) {
+func extractInfo(fcb *ast.FencedCodeBlock, source []byte) (string, string) {
 	if fcb.Info == nil {
-		return "", nil
+		return "", ""
 	}
 
 	return parseInfo(fcb.Info.Text(source))
 }
 
-func parseInfo(text []byte) (string, error) {
+func parseInfo(text []byte) (string, string) {
 	all := reInfo.FindSubmatch(text)
 	if all == nil {
-		return "", nil
+		return "", ""
 	}
 
-	var (
-		lang string
-		err  error
-	)
+	return string(all[1]), string(all[2])
+}
 
-	if len(all) > 1 {
-		lang = string(all[1])
+// parseAttrs parses the key=value attributes of the info string.
+// The value of an attribute without = is "true".
+func parseAttrs(str string) (map[string]string, error) {
+	fields, err := shlex.Split(str)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(all) <= reInfo.NumSubexp() {
-		return lang, nil
+	attrs := make(map[string]string, len(fields))
+
+	for _, field := range fields {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			value = "true"
+		}
+
+		attrs[key] = value
 	}
 
-	return lang, err
+	return attrs, nil
 }
 
 func asDefinitionTerm(node ast.Node, entering bool) *east.DefinitionTerm {
@@ -320,4 +596,7 @@ func asDefinitionList(node ast.Node, entering bool) *east.DefinitionList {
 var (
 	errRequiresCycle = errors.New("requires cycle")
 	errMissingTask   = errors.New("missing task")
+	errInvalidInfo   = errors.New("invalid code block info")
+	errNoName        = errors.New("missing task name")
+	errEmptyTask     = errors.New("task without code blocks and dependencies")
 )
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Problem is an error or a suspicious construct in the task definitions, with its location.
+type Problem struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (p *Problem) Error() string {
+	pos := strconv.Itoa(p.Line)
+	if p.Column > 0 {
+		pos = fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+
+	if len(p.File) == 0 {
+		return fmt.Sprintf("line %s: %s", pos, p.Err)
+	}
+
+	return fmt.Sprintf("%s:%s: %s", p.File, pos, p.Err)
+}
+
+func (p *Problem) Unwrap() error {
+	return p.Err
+}
+
+// Check loads the task definitions from the file like LoadFile, but instead of stopping at the first error,
+// it returns all the problems found. In addition to errors, the problems include the suspicious constructs
+// that do not prevent using the tasks: overrides without earlier definition
+// and task headings without code blocks and dependencies.
+// The tasks without errors (including the overridden ones) are returned too, in the order of definition.
+func Check(filename string) ([]*Task, []*Problem, error) {
+	taskdefs, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loader := newLoader()
+
+	if err := loader.load(filename, taskdefs, ""); err != nil {
+		return nil, nil, err
+	}
+
+	loader.checkRequires()
+
+	problems := slices.Concat(loader.errs, loader.warns)
+
+	SortProblems(problems)
+
+	return loader.all, problems, nil
+}
+
+// SortProblems sorts the problems by location.
+func SortProblems(problems []*Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+
+		return problems[i].Column < problems[j].Column
+	})
+}
@@ -0,0 +1,102 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node is a task invocation in the execution graph.
+type Node struct {
+	Task *Task
+	Args []string
+	Deps []*Node
+	// After is the previously requested task invocation, which must be finished (successfully or not) before this one.
+	After *Node
+}
+
+// Key identifies the task invocation by task name and arguments.
+func (n *Node) Key() string {
+	return key(n.Task.Name, n.Args)
+}
+
+func key(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), "\x00")
+}
+
+// ResolveAll builds the common dependency graph of several task invocations (task name followed by the arguments).
+// The returned nodes are in topological order, every task invocation (task name plus arguments) appears only once.
+// The requested invocations are executed in the given order: every one (with its dependencies not required
+// by the earlier ones) is after the previous one.
+func ResolveAll(tasks map[string]*Task, targets [][]string) ([]*Node, error) {
+	res := &resolver{
+		tasks:    tasks,
+		nodes:    make(map[string]*Node),
+		visiting: make(map[string]struct{}),
+	}
+
+	var prev *Node
+
+	for _, target := range targets {
+		start := len(res.order)
+
+		node, err := res.resolve(target[0], target[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		// the new dependencies are also executed after the previous invocation
+		if prev != nil {
+			for _, dep := range res.order[start:] {
+				dep.After = prev
+			}
+		}
+
+		prev = node
+	}
+
+	return res.order, nil
+}
+
+type resolver struct {
+	tasks    map[string]*Task
+	nodes    map[string]*Node
+	visiting map[string]struct{}
+	order    []*Node
+}
+
+func (r *resolver) resolve(name string, args []string) (*Node, error) {
+	id := key(name, args)
+
+	if node, done := r.nodes[id]; done {
+		return node, nil
+	}
+
+	if _, cycle := r.visiting[id]; cycle {
+		return nil, fmt.Errorf("%w: %s", errRequiresCycle, name)
+	}
+
+	task, found := r.tasks[name]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", errMissingTask, name)
+	}
+
+	r.visiting[id] = struct{}{}
+
+	node := &Node{Task: task, Args: args}
+
+	for _, req := range task.Requires {
+		dep, err := r.resolve(req[0], req[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		node.Deps = append(node.Deps, dep)
+	}
+
+	delete(r.visiting, id)
+
+	r.nodes[id] = node
+	r.order = append(r.order, node)
+
+	return node, nil
+}
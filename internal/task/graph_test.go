@@ -0,0 +1,147 @@
+package task_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/task"
+)
+
+func TestResolveAll(t *testing.T) {
+	t.Parallel()
+
+	tasks, err := task.Load(definitions(
+		[2]string{"all", "build, test"},
+		[2]string{"build", "gen"},
+		[2]string{"test", "gen, fixture x, fixture y"},
+		[2]string{"gen", ""},
+		[2]string{"fixture", ""},
+		[2]string{"lint", ""},
+		[2]string{"docs", "gen"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// node describes a node: key (name and arguments), dependencies and the node it comes after
+	type node struct {
+		key   string
+		deps  []string
+		after string
+	}
+
+	tests := []struct {
+		name    string
+		targets [][]string
+		want    []node
+	}{
+		{
+			name:    "single task",
+			targets: [][]string{{"lint"}},
+			want:    []node{{key: "lint"}},
+		},
+		{
+			name:    "arguments",
+			targets: [][]string{{"gen", "a", "b"}},
+			want:    []node{{key: "gen a b"}},
+		},
+		{
+			name:    "shared dependency",
+			targets: [][]string{{"all"}},
+			want: []node{
+				{key: "gen"},
+				{key: "build", deps: []string{"gen"}},
+				{key: "fixture x"},
+				{key: "fixture y"},
+				{key: "test", deps: []string{"gen", "fixture x", "fixture y"}},
+				{key: "all", deps: []string{"build", "test"}},
+			},
+		},
+		{
+			name:    "sequence",
+			targets: [][]string{{"lint"}, {"build"}, {"docs"}},
+			want: []node{
+				{key: "lint"},
+				{key: "gen", after: "lint"},
+				{key: "build", deps: []string{"gen"}, after: "lint"},
+				{key: "docs", deps: []string{"gen"}, after: "build"},
+			},
+		},
+		{
+			name:    "sequence of the same task",
+			targets: [][]string{{"fixture", "x"}, {"fixture", "y"}, {"fixture", "x"}},
+			want:    []node{{key: "fixture x"}, {key: "fixture y", after: "fixture x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			nodes, err := task.ResolveAll(tasks, tt.targets)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]node, 0, len(nodes))
+
+			for _, n := range nodes {
+				item := node{key: keyOf(n)}
+
+				for _, dep := range n.Deps {
+					item.deps = append(item.deps, keyOf(dep))
+				}
+
+				if n.After != nil {
+					item.after = keyOf(n.After)
+				}
+
+				got = append(got, item)
+			}
+
+			if !slices.EqualFunc(got, tt.want, func(a, b node) bool {
+				return a.key == b.key && a.after == b.after && slices.Equal(a.deps, b.deps)
+			}) {
+				t.Errorf("ResolveAll() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveAllErrors(t *testing.T) {
+	t.Parallel()
+
+	// the loader rejects cycles, so the tasks are created directly
+	tasks := map[string]*task.Task{
+		"a": {Name: "a", Requires: [][]string{{"b"}}},
+		"b": {Name: "b", Requires: [][]string{{"a"}}},
+		"c": {Name: "c", Requires: [][]string{{"missing"}}},
+	}
+
+	tests := []struct {
+		name    string
+		targets [][]string
+		err     string
+	}{
+		{"missing target", [][]string{{"nope"}}, "missing task: nope"},
+		{"missing dependency", [][]string{{"c"}}, "missing task: missing"},
+		{"cycle", [][]string{{"a"}}, "requires cycle: a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := task.ResolveAll(tasks, tt.targets)
+			if err == nil || !strings.Contains(err.Error(), tt.err) || errors.Unwrap(err) == nil {
+				t.Errorf("ResolveAll() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func keyOf(node *task.Node) string {
+	return strings.Join(append([]string{node.Task.Name}, node.Args...), " ")
+}
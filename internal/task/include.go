@@ -0,0 +1,138 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// loader loads the task definitions from a file and from the files included by it (recursively).
+// The tasks of the included files are defined before the tasks of the including file,
+// so the including file can override them.
+type loader struct {
+	tasks map[string]*Task
+	all   []*Task
+	errs  []*Problem
+	warns []*Problem
+	// stack contains the absolute names of the files being loaded, to detect include cycles
+	stack  []string
+	loaded map[string]struct{}
+}
+
+func newLoader() *loader {
+	return &loader{
+		tasks:  make(map[string]*Task),
+		loaded: make(map[string]struct{}),
+	}
+}
+
+// load loads the task definitions of the file. The tasks will be executed in the given directory
+// (empty means the directory of the task definitions).
+func (l *loader) load(filename string, taskdefs []byte, dir string) error {
+	absname, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+
+	l.stack = append(l.stack, absname)
+	l.loaded[absname] = struct{}{}
+
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	builder, err := parse(filename, taskdefs)
+	if err != nil {
+		return err
+	}
+
+	builder.dir = dir
+
+	for _, inc := range builder.includes {
+		if err := l.include(builder, inc); err != nil {
+			return err
+		}
+	}
+
+	builder.build(l.tasks)
+
+	l.all = append(l.all, builder.all...)
+	l.errs = append(l.errs, builder.errs...)
+	l.warns = append(l.warns, builder.warns...)
+
+	return nil
+}
+
+// include loads the file included by the task definitions of the builder.
+// The path of the included file is relative to the including file.
+// A file already included (for example by another file) is not loaded again.
+func (l *loader) include(builder *builder, inc *include) error {
+	filename := filepath.FromSlash(inc.path)
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(filepath.Dir(builder.filename), filename)
+	}
+
+	absname, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+
+	if idx := slices.Index(l.stack, absname); idx >= 0 {
+		chain := strings.Join(append(slices.Clone(l.stack[idx:]), absname), " -> ")
+		l.errs = append(l.errs, builder.problem(inc.line, fmt.Errorf("%w: %s", errIncludeCycle, chain)))
+
+		return nil
+	}
+
+	if _, done := l.loaded[absname]; done {
+		return nil
+	}
+
+	taskdefs, err := os.ReadFile(filename)
+	if err != nil {
+		l.errs = append(l.errs, builder.problem(inc.line, fmt.Errorf("%w: %w", errInclude, err)))
+
+		return nil
+	}
+
+	return l.load(filename, taskdefs, filepath.Dir(filename))
+}
+
+// checkRequires resolves and checks the dependencies of the tasks, after all the files are loaded.
+func (l *loader) checkRequires() {
+	for _, task := range l.all {
+		for _, req := range task.Requires {
+			req[0] = resolve(l.tasks, task.Namespace, req[0])
+		}
+	}
+
+	lookup := func(name string) (bool, [][]string) {
+		task, has := l.tasks[name]
+		if !has {
+			return false, nil
+		}
+
+		return true, task.Requires
+	}
+
+	for _, task := range l.all {
+		if l.tasks[task.Name] != task {
+			continue
+		}
+
+		for _, req := range task.Requires {
+			visited := map[string]struct{}{task.Name: {}}
+			if err := checkdep(req[0], lookup, visited); err != nil {
+				l.errs = append(l.errs, &Problem{File: task.File, Line: task.Line, Err: err})
+
+				break
+			}
+		}
+	}
+}
+
+var (
+	errInclude      = errors.New("include failed")
+	errIncludeCycle = errors.New("include cycle")
+)
@@ -0,0 +1,141 @@
+package task_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/task"
+)
+
+// files writes the files to a temporary directory and returns its name.
+func files(t *testing.T, contents map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range contents {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func taskdef(name string, extra string) string {
+	return "\n## " + name + " - Task " + name + "\n\n" + extra + "```bash\necho " + name + "\n```\n"
+}
+
+func TestLoadFileInclude(t *testing.T) {
+	t.Parallel()
+
+	dir := files(t, map[string]string{
+		"README.md": "# Main\n\nInclude\n: docs/a.md, b.md\n" +
+			taskdef("main", "Requires\n: a, b\n\n") +
+			taskdef("b", "Override\n: replace\n\n"),
+		"docs/a.md":   "# A\n\nInclude\n: ../b.md, c/c.md\n" + taskdef("a", ""),
+		"b.md":        "# B\n" + taskdef("b", ""),
+		"docs/c/c.md": "# C\n" + taskdef("c", ""),
+	})
+
+	tasks, err := task.LoadFile(filepath.Join(dir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		file string
+		dir  string
+	}{
+		{"main", "README.md", ""},
+		{"a", "docs/a.md", "docs"},
+		{"b", "README.md", ""},
+		{"c", "docs/c/c.md", "docs/c"},
+	}
+
+	if len(tasks) != len(tests) {
+		t.Errorf("LoadFile() returned %d tasks, want %d", len(tasks), len(tests))
+	}
+
+	for _, tt := range tests {
+		got, found := tasks[tt.name]
+		if !found {
+			t.Errorf("task %s not found", tt.name)
+
+			continue
+		}
+
+		if want := filepath.Join(dir, filepath.FromSlash(tt.file)); got.File != want {
+			t.Errorf("%s: File = %s, want %s", tt.name, got.File, want)
+		}
+
+		want := filepath.Join(dir, filepath.FromSlash(tt.dir))
+		if len(tt.dir) == 0 {
+			want = ""
+		}
+
+		if got.Dir != want {
+			t.Errorf("%s: Dir = %q, want %q", tt.name, got.Dir, want)
+		}
+	}
+}
+
+func TestLoadFileIncludeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name:  "self",
+			files: map[string]string{"README.md": "# Main\n\nInclude\n: README.md\n" + taskdef("main", "")},
+			err:   "include cycle",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"README.md": "# Main\n\nInclude\n: a.md\n" + taskdef("main", ""),
+				"a.md":      "# A\n\nInclude\n: b.md\n" + taskdef("a", ""),
+				"b.md":      "# B\n\nInclude\n: a.md\n" + taskdef("b", ""),
+			},
+			err: "include cycle",
+		},
+		{
+			name:  "missing",
+			files: map[string]string{"README.md": "# Main\n\nInclude\n: missing.md\n" + taskdef("main", "")},
+			err:   "missing.md",
+		},
+		{
+			name: "duplicate",
+			files: map[string]string{
+				"README.md": "# Main\n\nInclude\n: a.md\n" + taskdef("a", ""),
+				"a.md":      "# A\n" + taskdef("a", ""),
+			},
+			err: "duplicate task: a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := files(t, tt.files)
+
+			_, err := task.LoadFile(filepath.Join(dir, "README.md"))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("LoadFile() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// defaultInterpreters returns the built-in language to interpreter command mapping.
+// The mapping can be extended or overridden with the Interpreters definition list term.
+func defaultInterpreters() map[string][]string {
+	python := "python3"
+	if runtime.GOOS == "windows" {
+		python = "python"
+	}
+
+	pwsh := []string{"pwsh", "-NoProfile", "-NonInteractive", "-File"}
+
+	return map[string][]string{
+		"python":     {python},
+		"js":         {"node"},
+		"javascript": {"node"},
+		"node":       {"node"},
+		"pwsh":       pwsh,
+		"powershell": pwsh,
+		"go":         {"go", "run"},
+	}
+}
+
+// parseInterpreters parses comma separated lang=command pairs.
+func parseInterpreters(value string) map[string][]string {
+	interpreters := make(map[string][]string)
+
+	for _, part := range strings.Split(value, ",") {
+		lang, command, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+
+		args, err := shlex.Split(command)
+		if err == nil && len(args) != 0 {
+			interpreters[strings.TrimSpace(lang)] = args
+		}
+	}
+
+	return interpreters
+}
+
+func lookupOption(opts map[string][]string, name string) ([]string, bool) {
+	for key, values := range opts {
+		if strings.EqualFold(key, name) {
+			return values, true
+		}
+	}
+
+	return nil, false
+}
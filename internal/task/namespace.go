@@ -0,0 +1,35 @@
+package task
+
+import "strings"
+
+// NamespaceSeparator separates the namespace and the name in a qualified task name.
+const NamespaceSeparator = ":"
+
+// qualify returns the name qualified with the namespace.
+func qualify(namespace, name string) string {
+	if len(namespace) == 0 || len(name) == 0 {
+		return name
+	}
+
+	return namespace + NamespaceSeparator + name
+}
+
+// resolve returns the qualified name of the task referenced from the namespace.
+// The name is looked up in the namespace first, then in the enclosing namespaces.
+// Names not found are returned as is.
+func resolve(tasks map[string]*Task, namespace, name string) string {
+	for len(namespace) != 0 {
+		if qualified := qualify(namespace, name); tasks[qualified] != nil {
+			return qualified
+		}
+
+		idx := strings.LastIndex(namespace, NamespaceSeparator)
+		if idx < 0 {
+			break
+		}
+
+		namespace = namespace[:idx]
+	}
+
+	return name
+}
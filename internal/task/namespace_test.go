@@ -0,0 +1,112 @@
+package task_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/task"
+)
+
+const namespaces = `# Tasks
+
+## build - Build
+
+Requires
+: lint, tools:fmt
+
+` + "```bash\necho build\n```" + `
+
+## lint - Lint
+
+` + "```bash\necho lint\n```" + `
+
+## tools - Tools
+
+Namespace
+: true
+
+### fmt - Format
+
+Requires
+: lint, go:vet
+
+` + "```bash\necho fmt\n```" + `
+
+### lint - Lint tools
+
+` + "```bash\necho tools lint\n```" + `
+
+### go - Go tools
+
+Namespace
+: true
+
+#### vet - Vet
+
+Requires
+: lint, docs
+
+` + "```bash\necho vet\n```" + `
+
+## docs - Docs
+
+### site - Site
+
+Requires
+: lint
+
+` + "```bash\necho site\n```" + `
+`
+
+func TestLoadNamespaces(t *testing.T) {
+	t.Parallel()
+
+	tasks, err := task.Load([]byte(namespaces))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		requires  []string
+	}{
+		{"build", "", []string{"lint", "tools:fmt"}},
+		{"lint", "", nil},
+		{"tools", "", nil},
+		{"tools:fmt", "tools", []string{"tools:lint", "tools:go:vet"}},
+		{"tools:lint", "tools", nil},
+		{"tools:go", "tools", nil},
+		{"tools:go:vet", "tools:go", []string{"tools:lint", "docs"}},
+		{"docs", "", nil},
+		{"site", "", []string{"lint"}},
+	}
+
+	if len(tasks) != len(tests) {
+		t.Errorf("Load() returned %d tasks, want %d", len(tasks), len(tests))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, found := tasks[tt.name]
+			if !found {
+				t.Fatalf("task %s not found", tt.name)
+			}
+
+			if got.Namespace != tt.namespace {
+				t.Errorf("Namespace = %q, want %q", got.Namespace, tt.namespace)
+			}
+
+			requires := make([]string, 0, len(got.Requires))
+			for _, req := range got.Requires {
+				requires = append(requires, req[0])
+			}
+
+			if !slices.Equal(requires, tt.requires) && (len(requires) != 0 || len(tt.requires) != 0) {
+				t.Errorf("Requires = %v, want %v", requires, tt.requires)
+			}
+		})
+	}
+}
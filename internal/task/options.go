@@ -1,23 +1,91 @@
 package task
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/shlex"
+	"github.com/szkiba/cdo/internal/glob"
 )
 
-func getopts(task *Task, opts map[string]string) {
-	for key, value := range opts {
+func getopts(task *Task, opts map[string][]string) error {
+	for key, values := range opts {
 		switch strings.ToLower(key) {
 		case "requires":
-			parts := strings.Split(value, ",")
+			parts := strings.Split(strings.Join(values, ","), ",")
 			for _, part := range parts {
 				args, err := shlex.Split(part)
-				if err == nil {
+				if err == nil && len(args) != 0 {
 					task.Requires = append(task.Requires, args)
 				}
 			}
+		case "sources", "generates":
+			patterns, err := getpatterns(task, values)
+			if err != nil {
+				return err
+			}
+
+			if strings.EqualFold(key, "sources") {
+				task.Sources = append(task.Sources, patterns...)
+			} else {
+				task.Generates = append(task.Generates, patterns...)
+			}
+		case "params", "options":
+			for _, value := range values {
+				param, err := parseParam(value)
+				if err != nil {
+					return err
+				}
+
+				task.Params = append(task.Params, param)
+			}
+		case "timeout":
+			value := strings.TrimSpace(values[len(values)-1])
+
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout < 0 {
+				return fmt.Errorf("%w: %s: %s", errInvalidTimeout, task.Name, value)
+			}
+
+			task.Timeout = timeout
+		case "override":
+			value := strings.ToLower(strings.TrimSpace(values[len(values)-1]))
+			if value != overrideReplace && value != overrideExtend {
+				return fmt.Errorf("%w: %s: %s", errInvalidOverride, task.Name, value)
+			}
+
+			task.override = value
 		default:
 		}
 	}
+
+	return nil
 }
+
+// getpatterns returns the comma separated glob patterns.
+func getpatterns(task *Task, values []string) ([]string, error) {
+	var patterns []string
+
+	for _, part := range strings.Split(strings.Join(values, ","), ",") {
+		pattern := strings.TrimSpace(part)
+		if len(pattern) == 0 {
+			continue
+		}
+
+		if _, err := glob.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", errInvalidPattern, task.Name, pattern)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+var (
+	errInvalidPattern  = errors.New("invalid glob pattern")
+	errInvalidTimeout  = errors.New("invalid timeout")
+	errInvalidOverride = errors.New("invalid override (replace or extend)")
+)
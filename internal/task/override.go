@@ -0,0 +1,74 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	overrideReplace = "replace"
+	overrideExtend  = "extend"
+)
+
+// override resolves the redefinition of a task.
+//
+// Defining a task with the same name more than once is an error,
+// unless the later definition has the Override definition list term.
+// With the replace value the later definition replaces the earlier one,
+// with the extend value the code blocks, the dependencies, the parameters, the sources and the generated files
+// of the later definition are added to the earlier one.
+func override(prev *Task, next *Task) (*Task, error) {
+	switch next.override {
+	case overrideReplace:
+		return next, nil
+	case overrideExtend:
+		return extend(prev, next), nil
+	default:
+		return nil, fmt.Errorf("%w: %s (first defined at %s), use the Override term to replace or extend it",
+			errDuplicateTask, next.Name, location(prev.File, prev.Line))
+	}
+}
+
+func extend(prev *Task, next *Task) *Task {
+	if len(next.Long) != 0 {
+		if len(prev.Long) != 0 {
+			prev.Long += "\n\n"
+		}
+
+		prev.Long += next.Long
+	}
+
+	prev.Steps = append(prev.Steps, next.Steps...)
+	prev.Requires = append(prev.Requires, next.Requires...)
+	prev.Sources = append(prev.Sources, next.Sources...)
+	prev.Generates = append(prev.Generates, next.Generates...)
+
+	for _, param := range next.Params {
+		idx := slices.IndexFunc(prev.Params, func(p *Param) bool { return p.Name == param.Name })
+		if idx < 0 {
+			prev.Params = append(prev.Params, param)
+		} else {
+			prev.Params[idx] = param
+		}
+	}
+
+	if next.Timeout > 0 {
+		prev.Timeout = next.Timeout
+	}
+
+	return prev
+}
+
+func location(file string, line int) string {
+	if len(file) == 0 {
+		return fmt.Sprintf("line %d", line)
+	}
+
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+var (
+	errDuplicateTask     = errors.New("duplicate task")
+	errNothingToOverride = errors.New("nothing to override")
+)
@@ -0,0 +1,129 @@
+package task_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/task"
+)
+
+const overridden = "# Tasks\n\n## build - Build\n\nFirst.\n\n" +
+	"Params\n: level=info (enum: info, debug)\n: out=bin\n\nRequires\n: gen\n\nSources\n: *.go\n\n" +
+	"```bash\necho first\n```\n\n## gen - Generate\n\n```bash\necho gen\n```\n\n" +
+	"## lint - Lint\n\n```bash\necho lint\n```\n"
+
+func TestLoadOverride(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		taskdefs string
+		steps    []string
+		requires []string
+		params   []string
+		sources  []string
+		long     []string
+		without  string
+		err      string
+	}{
+		{
+			name:     "duplicate",
+			taskdefs: "\n## build - Again\n\n```bash\necho second\n```\n",
+			err:      "duplicate task: build (first defined at line 3)",
+		},
+		{
+			name:     "replace",
+			taskdefs: "\n## build - Again\n\nSecond.\n\nOverride\n: replace\n\n```bash\necho second\n```\n",
+			steps:    []string{"echo second"},
+			long:     []string{"build - Again", "Second."},
+			without:  "First.",
+		},
+		{
+			name: "extend",
+			taskdefs: "\n## build - Again\n\nSecond.\n\nOverride\n: extend\n\n" +
+				"Params\n: level=debug (enum: info, debug)\n: verbose (bool)\n\nRequires\n: lint\n\nSources\n: go.mod\n\n" +
+				"```bash\necho second\n```\n",
+			steps:    []string{"echo first", "echo second"},
+			requires: []string{"gen", "lint"},
+			params:   []string{"level=debug", "out=bin", "verbose="},
+			sources:  []string{"*.go", "go.mod"},
+			long:     []string{"build - Build", "First.", "build - Again", "Second."},
+		},
+		{
+			name:     "invalid override",
+			taskdefs: "\n## build - Again\n\nOverride\n: merge\n\n```bash\necho second\n```\n",
+			err:      "build: merge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tasks, err := task.Load([]byte(overridden + tt.taskdefs))
+
+			if len(tt.err) != 0 {
+				var problem *task.Problem
+				if !errors.As(err, &problem) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			build := tasks["build"]
+
+			var steps, requires, params []string
+
+			for _, step := range build.Steps {
+				steps = append(steps, strings.TrimSpace(string(step.Script)))
+			}
+
+			for _, req := range build.Requires {
+				requires = append(requires, req[0])
+			}
+
+			for _, param := range build.Params {
+				params = append(params, param.Name+"="+param.Default)
+			}
+
+			if !slices.Equal(steps, tt.steps) {
+				t.Errorf("Steps = %q, want %q", steps, tt.steps)
+			}
+
+			if !slices.Equal(requires, tt.requires) {
+				t.Errorf("Requires = %v, want %v", requires, tt.requires)
+			}
+
+			if !slices.Equal(params, tt.params) {
+				t.Errorf("Params = %v, want %v", params, tt.params)
+			}
+
+			if !slices.Equal(build.Sources, tt.sources) {
+				t.Errorf("Sources = %v, want %v", build.Sources, tt.sources)
+			}
+
+			// the parts of the long description in order
+			long := build.Long
+
+			for _, part := range tt.long {
+				idx := strings.Index(long, part)
+				if idx < 0 {
+					t.Fatalf("Long = %q, want parts %q", build.Long, tt.long)
+				}
+
+				long = long[idx+len(part):]
+			}
+
+			if len(tt.without) != 0 && strings.Contains(build.Long, tt.without) {
+				t.Errorf("Long = %q contains %q", build.Long, tt.without)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Param is a declared task parameter.
+//
+// Parameters are declared using the Params (or Options) definition list term,
+// one definition description per parameter in the following format:
+//
+//	name[=default] [(type)] [- description]
+//
+// The type is one of string (default), bool, int or enum with the allowed values (enum: a, b, c).
+type Param struct {
+	Name        string
+	Type        string
+	Default     string
+	Enum        []string
+	Description string
+}
+
+const (
+	paramString = "string"
+	paramBool   = "bool"
+	paramInt    = "int"
+	paramEnum   = "enum"
+)
+
+// reservedParams are the names of the cdo command line flags. Parameters with these names would redefine
+// or hide the flags of the task command.
+var reservedParams = []string{ //nolint:gochecknoglobals
+	"help", "version", "env", "file", "makefile", "jobs", "dry-run", "grace-period", "timeout", "merge-output",
+	"watch", "watch-glob", "force", "utils", "busybox", "keep-going", "graph", "list", "format", "recursive",
+	"check", "completion",
+}
+
+var reParam = regexp.MustCompile(
+	`^\s*([A-Za-z_][\w-]*)(?:\s*=\s*("[^"]*"|[^\s(]*))?\s*(?:\(\s*(\w+)\s*(?::\s*([^)]*))?\))?\s*(?:-\s+(.*))?$`,
+)
+
+func parseParam(value string) (*Param, error) {
+	all := reParam.FindStringSubmatch(value)
+	if all == nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidParam, value)
+	}
+
+	param := &Param{
+		Name:        all[1],
+		Type:        strings.ToLower(all[3]),
+		Default:     strings.Trim(all[2], `"`),
+		Description: strings.TrimSpace(all[5]),
+	}
+
+	if slices.Contains(reservedParams, param.Name) {
+		return nil, fmt.Errorf("%w: %s", errReservedParam, param.Name)
+	}
+
+	switch param.Type {
+	case "":
+		param.Type = paramString
+	case paramEnum:
+		for _, item := range strings.Split(all[4], ",") {
+			if item = strings.TrimSpace(item); len(item) != 0 {
+				param.Enum = append(param.Enum, item)
+			}
+		}
+
+		if len(param.Enum) == 0 {
+			return nil, fmt.Errorf("%w: %s: missing enum values", errInvalidParam, value)
+		}
+	case paramString, paramBool, paramInt:
+	default:
+		return nil, fmt.Errorf("%w: %s: unknown type %s", errInvalidParam, value, param.Type)
+	}
+
+	if len(param.Default) != 0 {
+		if err := param.Validate(param.Default); err != nil {
+			return nil, err
+		}
+	}
+
+	return param, nil
+}
+
+// Var returns the name of the shell variable of the parameter.
+func (p *Param) Var() string {
+	return strings.ReplaceAll(p.Name, "-", "_")
+}
+
+// Validate checks whether the value is valid for the parameter type.
+func (p *Param) Validate(value string) error {
+	var err error
+
+	switch p.Type {
+	case paramBool:
+		_, err = strconv.ParseBool(value)
+	case paramInt:
+		_, err = strconv.Atoi(value)
+	case paramEnum:
+		if !slices.Contains(p.Enum, value) {
+			err = fmt.Errorf("%w, allowed values: %s", errNotAllowed, strings.Join(p.Enum, ", "))
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s=%s: %w", errInvalidValue, p.Name, value, err)
+	}
+
+	return nil
+}
+
+func (p *Param) usage() string {
+	if p.Type == paramEnum {
+		return strings.TrimSpace(fmt.Sprintf("%s (one of: %s)", p.Description, strings.Join(p.Enum, ", ")))
+	}
+
+	return p.Description
+}
+
+// AddFlags registers a flag for each parameter of the task.
+func (t *Task) AddFlags(flags *pflag.FlagSet) {
+	for _, param := range t.Params {
+		switch param.Type {
+		case paramBool:
+			flags.Bool(param.Name, param.Default == "true", param.usage())
+		case paramInt:
+			value, _ := strconv.Atoi(param.Default)
+			flags.Int(param.Name, value, param.usage())
+		default:
+			flags.String(param.Name, param.Default, param.usage())
+		}
+	}
+}
+
+// FlagArgs returns the changed parameter flags as command line arguments.
+func (t *Task) FlagArgs(flags *pflag.FlagSet) []string {
+	var args []string
+
+	for _, param := range t.Params {
+		if flag := flags.Lookup(param.Name); flag != nil && flag.Changed {
+			args = append(args, "--"+param.Name+"="+flag.Value.String())
+		}
+	}
+
+	return args
+}
+
+// Bind parses the parameter flags from the arguments and returns the parameter variables and the positional arguments.
+// The value of a parameter comes from the flag, from the variable of the same name in env, or from the default value.
+// All values are validated.
+func (t *Task) Bind(args []string, env map[string]string) (map[string]string, []string, error) {
+	if len(t.Params) == 0 {
+		return nil, args, nil
+	}
+
+	flags := pflag.NewFlagSet(t.Name, pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist = pflag.ParseErrorsWhitelist{UnknownFlags: true}
+
+	t.AddFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", t.Name, err)
+	}
+
+	vars := make(map[string]string, len(t.Params))
+
+	for _, param := range t.Params {
+		flag := flags.Lookup(param.Name)
+
+		value, has := env[param.Var()]
+
+		switch {
+		case flag.Changed:
+			value = flag.Value.String()
+		case has:
+		case len(param.Default) != 0 || param.Type == paramBool:
+			value = flag.Value.String()
+		default:
+			continue
+		}
+
+		if err := param.Validate(value); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", t.Name, err)
+		}
+
+		vars[param.Var()] = value
+	}
+
+	return vars, flags.Args(), nil
+}
+
+var (
+	errInvalidParam = errors.New("invalid parameter declaration")
+	errInvalidValue = errors.New("invalid parameter value")
+	errNotAllowed   = errors.New("value not allowed")
+
+	errReservedParam = errors.New("parameter name is reserved for a cdo flag")
+)
@@ -0,0 +1,146 @@
+package task
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestParseParam(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  *Param
+		err   error
+	}{
+		{"name", &Param{Name: "name", Type: paramString}, nil},
+		{"name=foo", &Param{Name: "name", Type: paramString, Default: "foo"}, nil},
+		{`name="foo bar"`, &Param{Name: "name", Type: paramString, Default: "foo bar"}, nil},
+		{"verbose (bool) - Be verbose", &Param{Name: "verbose", Type: paramBool, Description: "Be verbose"}, nil},
+		{"count=3 (INT)", &Param{Name: "count", Type: paramInt, Default: "3"}, nil},
+		{
+			"level=info (enum: debug, info ,warn) - Log level",
+			&Param{
+				Name: "level", Type: paramEnum, Default: "info", Enum: []string{"debug", "info", "warn"},
+				Description: "Log level",
+			},
+			nil,
+		},
+		{"dry-run-mode (bool)", &Param{Name: "dry-run-mode", Type: paramBool}, nil},
+		{"count=three (int)", nil, errInvalidValue},
+		{"level=trace (enum: debug, info)", nil, errInvalidValue},
+		{"level (enum: )", nil, errInvalidParam},
+		{"size (float)", nil, errInvalidParam},
+		{"1st", nil, errInvalidParam},
+		{"help (bool)", nil, errReservedParam},
+		{"force", nil, errReservedParam},
+		{"file=x", nil, errReservedParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseParam(tt.value)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("parseParam() error = %v, want %v", err, tt.err)
+			}
+
+			if tt.want == nil {
+				return
+			}
+
+			if got.Name != tt.want.Name || got.Type != tt.want.Type || got.Default != tt.want.Default ||
+				got.Description != tt.want.Description || !slices.Equal(got.Enum, tt.want.Enum) {
+				t.Errorf("parseParam() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskBind(t *testing.T) {
+	t.Parallel()
+
+	params := []string{"name=world", "loud (bool)", "count (int)", "level=info (enum: info, debug)", "dry-mode (bool)"}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		vars map[string]string
+		rest []string
+		err  error
+	}{
+		{
+			name: "defaults",
+			vars: map[string]string{"name": "world", "loud": "false", "level": "info", "dry_mode": "false"},
+		},
+		{
+			name: "flags",
+			args: []string{"--name", "cdo", "--loud", "--count=2", "--dry-mode", "a", "b"},
+			vars: map[string]string{"name": "cdo", "loud": "true", "count": "2", "level": "info", "dry_mode": "true"},
+			rest: []string{"a", "b"},
+		},
+		{
+			name: "environment",
+			args: []string{"--level=debug"},
+			env:  map[string]string{"name": "env", "count": "5", "level": "info"},
+			vars: map[string]string{"name": "env", "loud": "false", "count": "5", "level": "debug", "dry_mode": "false"},
+		},
+		{
+			name: "terminated flags",
+			args: []string{"--loud", "--", "--name", "x"},
+			vars: map[string]string{"name": "world", "loud": "true", "level": "info", "dry_mode": "false"},
+			rest: []string{"--name", "x"},
+		},
+		{name: "invalid flag", args: []string{"--level=trace"}, err: errInvalidValue},
+		{name: "invalid environment", env: map[string]string{"count": "many"}, err: errInvalidValue},
+	}
+
+	task := &Task{Name: "greet"}
+
+	for _, value := range params {
+		param, err := parseParam(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		task.Params = append(task.Params, param)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vars, rest, err := task.Bind(tt.args, tt.env)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Bind() error = %v, want %v", err, tt.err)
+			}
+
+			if tt.err != nil {
+				return
+			}
+
+			if !maps.Equal(vars, tt.vars) {
+				t.Errorf("Bind() vars = %v, want %v", vars, tt.vars)
+			}
+
+			if len(rest) != 0 || len(tt.rest) != 0 {
+				if !slices.Equal(rest, tt.rest) {
+					t.Errorf("Bind() args = %v, want %v", rest, tt.rest)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskBindNoParams(t *testing.T) {
+	t.Parallel()
+
+	vars, rest, err := (&Task{Name: "plain"}).Bind([]string{"--flag", "a"}, nil)
+	if err != nil || vars != nil || !slices.Equal(rest, []string{"--flag", "a"}) {
+		t.Errorf("Bind() = %v, %v, %v", vars, rest, err)
+	}
+}
@@ -0,0 +1,80 @@
+package task_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/szkiba/cdo/internal/task"
+)
+
+// definitions returns a task definition file with the tasks given as name and required tasks pairs.
+func definitions(tasks ...[2]string) []byte {
+	var buff strings.Builder
+
+	buff.WriteString("# Tasks\n")
+
+	for _, t := range tasks {
+		fmt.Fprintf(&buff, "\n## %s - Task %s\n\n", t[0], t[0])
+
+		if len(t[1]) != 0 {
+			fmt.Fprintf(&buff, "Requires\n: %s\n\n", t[1])
+		}
+
+		fmt.Fprintf(&buff, "```bash\necho %s\n```\n", t[0])
+	}
+
+	return []byte(buff.String())
+}
+
+func TestLoadRequires(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		tasks [][2]string
+		err   string
+	}{
+		{"chain", [][2]string{{"a", "b"}, {"b", "c"}, {"c", ""}}, ""},
+		{"diamond", [][2]string{{"a", "b, c"}, {"b", "d"}, {"c", "d"}, {"d", ""}}, ""},
+		{
+			"nested diamond",
+			[][2]string{{"ci", "build"}, {"build", "gen, compile"}, {"compile", "gen"}, {"gen", ""}},
+			"",
+		},
+		{"same task with different arguments", [][2]string{{"a", "b x, b y"}, {"b", ""}}, ""},
+		{"empty entries", [][2]string{{"a", "b, , c,"}, {"b", ""}, {"c", ""}}, ""},
+		{"only separators", [][2]string{{"a", ", ,"}}, ""},
+		{"self cycle", [][2]string{{"a", "a"}}, "requires cycle: a"},
+		{"cycle", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}}, "requires cycle: a"},
+		{"cycle below diamond", [][2]string{{"a", "b, c"}, {"b", "d"}, {"c", "d"}, {"d", "c"}}, "requires cycle: d"},
+		{"missing", [][2]string{{"a", "b"}}, "missing task: b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := task.Load(definitions(tt.tasks...))
+
+			switch {
+			case len(tt.err) == 0 && err != nil:
+				t.Fatalf("Load() error = %v", err)
+			case len(tt.err) != 0 && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("Load() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadRequiresProblem(t *testing.T) {
+	t.Parallel()
+
+	_, err := task.Load(definitions([2]string{"a", "a"}))
+
+	var problem *task.Problem
+	if !errors.As(err, &problem) || problem.Line != 3 {
+		t.Errorf("Load() error = %#v, want problem at line 3", err)
+	}
+}
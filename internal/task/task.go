@@ -1,6 +1,12 @@
 package task
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
@@ -8,26 +14,142 @@ import (
 	"github.com/yuin/goldmark/util"
 )
 
+// Task is a task definition. The Name of a task in a namespace is qualified with the Namespace (namespace:name).
+// Sources and Generates are glob patterns of the input and output files (relative to the task definitions directory),
+// they allow skipping the task when it is up to date.
 type Task struct {
-	Name     string
-	Short    string
-	Long     string
-	Script   []byte
-	Requires [][]string
+	Name      string
+	Namespace string
+	Short     string
+	Long      string
+	Steps     []*Step
+	Requires  [][]string
+	Params    []*Param
+	Timeout   time.Duration
+	Sources   []string
+	Generates []string
+	File      string
+	Line      int
+	Dir       string
+	override  string
+}
+
+// Step is a runnable code block of the task.
+// The steps of a task are executed in order, in the same shell session.
+//
+// The attributes of the step come from the info string of the code block (key=value pairs after the language).
+// The dir attribute is the working directory of the step (relative to the task definitions directory),
+// the os attribute is a comma separated list of operating systems on which the step is executed,
+// the if attribute is a shell condition, the step is executed only if the condition succeeds.
+// Code blocks with the ignore attribute are for documentation only, they are not steps.
+//
+// Steps with an Interpreter (such as python or node) are executed by writing the script
+// to a temporary file and invoking the interpreter with the file name and the positional arguments.
+//
+// Line is the line of the opening fence in the task definition file,
+// Indent is the indentation of the code block (for example inside a list item).
+// Base is the directory of the included file defining the step (empty for the main file),
+// the step is executed in this directory.
+type Step struct {
+	Lang        string
+	Script      []byte
+	Interpreter []string
+	Dir         string
+	OS          []string
+	If          string
+	Attrs       map[string]string
+	File        string
+	Line        int
+	Indent      int
+	Base        string
+}
+
+// Position returns the line and column in the task definition file
+// of the given (1-based) line and column of the script.
+func (s *Step) Position(line, col int) (int, int) {
+	return s.Line + line, s.Indent + col
+}
+
+// Embedded reports whether the step is executed by the embedded shell.
+func (s *Step) Embedded() bool {
+	return len(s.Interpreter) == 0
+}
+
+// Supports reports whether the step should be executed on the given operating system.
+func (s *Step) Supports(goos string) bool {
+	return len(s.OS) == 0 || slices.Contains(s.OS, goos)
+}
+
+// Extension returns the file name extension of the script file of a step with interpreter.
+func (s *Step) Extension() string {
+	switch s.Lang {
+	case "python":
+		return ".py"
+	case "js", "javascript", "node":
+		return ".js"
+	case "pwsh", "powershell":
+		return ".ps1"
+	default:
+		return "." + s.Lang
+	}
+}
+
+// Script returns the concatenated scripts of the steps executed by the embedded shell.
+func (t *Task) Script() []byte {
+	var buff bytes.Buffer
+
+	for _, step := range t.Steps {
+		if step.Embedded() {
+			buff.Write(step.Script)
+		}
+	}
+
+	return buff.Bytes()
+}
+
+// LoadFile loads the task definitions from the file and from the files included by it.
+// The File field of the tasks will contain the name of the file defining the task,
+// the Dir field of the included tasks will contain the directory of the included file.
+func LoadFile(filename string) (map[string]*Task, error) {
+	taskdefs, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return load(filename, taskdefs)
 }
 
 func Load(taskdefs []byte) (map[string]*Task, error) {
+	return load("", taskdefs)
+}
+
+func load(filename string, taskdefs []byte) (map[string]*Task, error) {
+	loader := newLoader()
+
+	if err := loader.load(filename, taskdefs, ""); err != nil {
+		return nil, err
+	}
+
+	loader.checkRequires()
+
+	if len(loader.errs) != 0 {
+		return nil, loader.errs[0]
+	}
+
+	return loader.tasks, nil
+}
+
+func parse(filename string, taskdefs []byte) (*builder, error) {
 	parser := newParser()
 	reader := text.NewReader(taskdefs)
 	root := parser.Parse(reader).OwnerDocument()
-	builder := newBuilder(taskdefs)
+	builder := newBuilder(filename, taskdefs)
 
-	err := ast.Walk(root, builder.walk)
-	if err != nil {
+	if err := ast.Walk(root, builder.walk); err != nil {
 		return nil, err
 	}
 
-	return builder.build()
+	return builder, nil
 }
 
 func newParser() parser.Parser { //nolint:ireturn
@@ -0,0 +1,118 @@
+package workspace
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/szkiba/cdo/internal/glob"
+)
+
+// rule is a pattern of a .gitignore file.
+type rule struct {
+	// base is the slash separated path of the directory containing the .gitignore file
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// loadIgnore appends the rules of the .gitignore file of the directory (if any) to the rules.
+func loadIgnore(dir string, rel string, rules []*rule) ([]*rule, error) {
+	file, err := os.Open(filepath.Clean(filepath.Join(dir, ".gitignore")))
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close() //nolint:errcheck
+
+	// the rules of the parent directories are shared, so the slice is copied before appending
+	rules = append([]*rule{}, rules...)
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if r := parseRule(rel, scanner.Text()); r != nil {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules, scanner.Err()
+}
+
+func parseRule(base string, line string) *rule {
+	line = strings.TrimRight(line, " \t\r")
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	r := &rule{base: base}
+
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	// a pattern with a separator (at the beginning or in the middle) is relative to the .gitignore file
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	re, err := regexp.Compile(globRegexp(line, anchored))
+	if err != nil {
+		return nil
+	}
+
+	r.re = re
+
+	return r
+}
+
+// globRegexp converts the gitignore pattern to regular expression.
+// The pattern without separator matches in any directory.
+func globRegexp(pattern string, anchored bool) string {
+	if anchored {
+		return "^" + glob.Regexp(pattern) + "$"
+	}
+
+	return "^(?:.*/)?" + glob.Regexp(pattern) + "$"
+}
+
+// ignored reports whether the slash separated path (relative to the workspace root) is ignored.
+// As in git, the last matching rule wins.
+func ignored(rules []*rule, path string, isDir bool) bool {
+	result := false
+
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+
+		sub := path
+
+		if len(r.base) != 0 {
+			if !strings.HasPrefix(path, r.base+"/") {
+				continue
+			}
+
+			sub = path[len(r.base)+1:]
+		}
+
+		if r.re.MatchString(sub) {
+			result = !r.negate
+		}
+	}
+
+	return result
+}
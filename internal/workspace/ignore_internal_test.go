@@ -0,0 +1,132 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	t.Parallel()
+
+	for _, line := range []string{"", "   ", "# comment"} {
+		if r := parseRule("", line); r != nil {
+			t.Errorf("parseRule(%q) = %+v, want nil", line, r)
+		}
+	}
+
+	r := parseRule("sub", "!/build/ ")
+	if r == nil || !r.negate || !r.dirOnly || r.base != "sub" {
+		t.Errorf("parseRule(!/build/) = %+v", r)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	t.Parallel()
+
+	type rules struct {
+		base  string
+		lines []string
+	}
+
+	tests := []struct {
+		name  string
+		rules []rules
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"no rules", nil, "a", true, false},
+		{"name in any directory", []rules{{"", []string{"node_modules"}}}, "web/node_modules", true, true},
+		{"name of file", []rules{{"", []string{"*.log"}}}, "logs/x.log", false, true},
+		{"anchored", []rules{{"", []string{"/build"}}}, "build", true, true},
+		{"anchored not in subdirectory", []rules{{"", []string{"/build"}}}, "sub/build", true, false},
+		{"middle separator is anchored", []rules{{"", []string{"a/b"}}}, "x/a/b", true, false},
+		{"directory only", []rules{{"", []string{"out/"}}}, "out", false, false},
+		{"directory only matches directory", []rules{{"", []string{"out/"}}}, "out", true, true},
+		{"double star", []rules{{"", []string{"**/tmp"}}}, "a/b/tmp", true, true},
+		{"negation", []rules{{"", []string{"*", "!keep"}}}, "keep", true, false},
+		{"last match wins", []rules{{"", []string{"!keep", "*"}}}, "keep", true, true},
+		{"nested file base", []rules{{"lib", []string{"gen"}}}, "lib/x/gen", true, true},
+		{"nested file outside base", []rules{{"lib", []string{"gen"}}}, "app/gen", true, false},
+		{"nested file anchored", []rules{{"lib", []string{"/gen"}}}, "lib/gen", true, true},
+		{"nested negation", []rules{{"", []string{"gen"}}, {"lib", []string{"!gen"}}}, "lib/gen", true, false},
+	}
+
+	for _, tt := range tests {
+		var all []*rule
+
+		for _, set := range tt.rules {
+			for _, line := range set.lines {
+				if r := parseRule(set.base, line); r != nil {
+					all = append(all, r)
+				}
+			}
+		}
+
+		if got := ignored(all, tt.path, tt.isDir); got != tt.want {
+			t.Errorf("%s: ignored(%q) = %v, want %v", tt.name, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	files := map[string]string{
+		"CONTRIBUTING.md":                 "",
+		".gitignore":                      "vendor/\n",
+		"app/CONTRIBUTING.md":             "",
+		"lib/docs/CONTRIBUTING.md":        "",
+		"lib/.gitignore":                  "*\n!.gitignore\n",
+		"tools/.github/CONTRIBUTING.md":   "",
+		"vendor/x/CONTRIBUTING.md":        "",
+		"app/nested/CONTRIBUTING.md":      "",
+		".git/modules/x/CONTRIBUTING.md":  "",
+		"docs/CONTRIBUTING.md":            "",
+		"empty/README.md":                 "",
+		"tools/sub/docs/CONTRIBUTING.md/": "",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects, err := Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+
+	for _, project := range projects {
+		paths = append(paths, project.Path)
+	}
+
+	// the root docs/CONTRIBUTING.md belongs to the root, lib is ignored by its own .gitignore,
+	// a CONTRIBUTING.md directory is not a task definition file
+	want := []string{"app", "app/nested", "tools"}
+
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Discover() = %q, want %q", paths, want)
+	}
+}
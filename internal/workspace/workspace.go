@@ -0,0 +1,125 @@
+// Package workspace discovers the projects (directories with task definition file) of a directory tree.
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const contributing = "CONTRIBUTING.md"
+
+// Project is a directory with a task definition file.
+type Project struct {
+	// Path is the slash separated path of the project directory relative to the workspace root.
+	Path string
+	Dir  string
+	File string
+}
+
+// Candidates returns the possible task definition files of the directory, in order of precedence.
+func Candidates(dir string) []string {
+	return []string{
+		filepath.Clean(filepath.Join(dir, contributing)),
+		filepath.Clean(filepath.Join(dir, "docs", contributing)),
+		filepath.Clean(filepath.Join(dir, ".github", contributing)),
+	}
+}
+
+// Lookup returns the project in the directory given by the slash separated path relative to the root.
+func Lookup(root string, path string) (*Project, bool) {
+	dir := filepath.Join(root, filepath.FromSlash(path))
+
+	filename, found := definitions(dir)
+	if !found {
+		return nil, false
+	}
+
+	return &Project{Path: filepath.ToSlash(filepath.Clean(path)), Dir: dir, File: filename}, true
+}
+
+// Discover returns the projects in the subdirectories of the root directory, sorted by path.
+// The directories ignored by .gitignore files are skipped.
+func Discover(root string) ([]*Project, error) {
+	w := &walker{root: root, claimed: make(map[string]struct{})}
+
+	for _, filename := range Candidates(root) {
+		w.claimed[filename] = struct{}{}
+	}
+
+	if err := w.walk(root, "", nil); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(w.projects, func(i, j int) bool { return w.projects[i].Path < w.projects[j].Path })
+
+	return w.projects, nil
+}
+
+func definitions(dir string) (string, bool) {
+	for _, filename := range Candidates(dir) {
+		if info, err := os.Stat(filename); err == nil && !info.IsDir() {
+			return filename, true
+		}
+	}
+
+	return "", false
+}
+
+type walker struct {
+	root     string
+	projects []*Project
+	// claimed contains the task definition files already belonging to a project
+	claimed map[string]struct{}
+}
+
+func (w *walker) walk(dir string, rel string, rules []*rule) error {
+	rules, err := loadIgnore(dir, rel, rules)
+	if err != nil {
+		return err
+	}
+
+	if len(rel) != 0 {
+		if filename, found := definitions(dir); found && !w.ignored(rules, filename) {
+			if _, done := w.claimed[filename]; !done {
+				w.claimed[filename] = struct{}{}
+				w.projects = append(w.projects, &Project{Path: rel, Dir: dir, File: filename})
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == ".git" {
+			continue
+		}
+
+		sub := entry.Name()
+		if len(rel) != 0 {
+			sub = rel + "/" + sub
+		}
+
+		if ignored(rules, sub, true) {
+			continue
+		}
+
+		if err := w.walk(filepath.Join(dir, entry.Name()), sub, rules); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *walker) ignored(rules []*rule, filename string) bool {
+	rel, err := filepath.Rel(w.root, filename)
+	if err != nil {
+		return false
+	}
+
+	return ignored(rules, filepath.ToSlash(rel), false)
+}
@@ -1,20 +1,37 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/szkiba/cdo/internal/cmd"
+	"github.com/szkiba/cdo/internal/shell"
 	"mvdan.cc/sh/v3/interp"
 )
 
 func main() {
+	ctx, stop := shell.NotifyContext(context.Background())
+	defer stop()
+
 	root, err := cmd.New(os.Args[1:])
 	cobra.CheckErr(err)
 
-	err = root.Execute()
+	err = root.ExecuteContext(ctx)
+
+	if status, ok := shell.InterruptStatus(ctx); ok {
+		os.Exit(status) //nolint:gocritic
+	}
 
 	if status, ok := interp.IsExitStatus(err); ok {
+		var serr *shell.StatusError
+
+		if errors.As(err, &serr) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+
 		os.Exit(int(status))
 	}
 